@@ -3,7 +3,9 @@ package main
 import (
 	"fmt"
 	"os"
-	"time"
+	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/aws/aws-cdk-go/awscdk/v2"
 	"github.com/aws/jsii-runtime-go"
@@ -12,84 +14,192 @@ import (
 )
 
 func main() {
+	err := run()
+	// jsii runtime has to be closed explicitly, deferred calls don't run on os.Exit
+	jsii.Close()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
 	var (
 		err             error
 		app             awscdk.App
-		awsStackProps   awscdk.StackProps
 		props           authorizer.StackProps
 		authorizerStack authorizer.Stack
+		deployDemo      bool
 	)
-	defer jsii.Close()
 	app = awscdk.NewApp(nil)
 
-	awsStackProps = awscdk.StackProps{
-		Env: env(),
-	}
 	props = authorizer.StackProps{
-		StackProps: awsStackProps,
+		StackProps: awscdk.StackProps{
+			Env: env(),
+		},
 	}
 
 	if err = readStackProps(app, &props); err != nil {
-		fmt.Printf("could not read context values %s", err)
-		return
+		return fmt.Errorf("could not read context values %w", err)
 	}
 
 	if authorizerStack, err = authorizer.NewStack(app, "CloudentityAWSAuthorizer", props); err != nil {
-		fmt.Printf("could not create stack %s", err)
-		return
+		return fmt.Errorf("could not create stack %w", describeValidationErrors(err))
+	}
+
+	if err = readContext(app, "deployDemo", &deployDemo); err != nil {
+		return fmt.Errorf("could not read context values %w", err)
 	}
 
-	if readBoolCtxParam(app, "deployDemo") {
+	if deployDemo {
 		fmt.Println("Deploying demo stack")
-		if _, err = demo.NewStack(app, "DemoAPIStack", authorizerStack.AuthorizerLambda, awsStackProps); err != nil {
-			fmt.Printf("could not create demo stack %s", err)
-			return
+		if _, err = demo.NewStack(app, "DemoAPIStack", authorizerStack.AuthorizerHandler(), awscdk.StackProps{Env: props.Env}); err != nil {
+			return fmt.Errorf("could not create demo stack %w", err)
 		}
 	}
 
 	app.Synth(nil)
+	return nil
 }
 
 func readStackProps(app awscdk.App, props *authorizer.StackProps) error {
-	var err error
-	props.SyncZip = readCtxParam(app, "syncZip")
-	props.AuthorizerZip = readCtxParam(app, "authorizerZip")
-	props.ManuallyCreateAuthorizer = readBoolCtxParam(app, "manuallyCreateAuthorizer")
-	props.ClientID = readCtxParam(app, "clientID")
+	var (
+		err         error
+		configFile  string
+		environment string
+	)
+
+	if err = readContext(app, "configFile", &configFile); err != nil {
+		return err
+	}
+	if err = readContext(app, "env", &environment); err != nil {
+		return err
+	}
+
+	// config file and environment profile are read first, so context params and env vars take precedence over them
+	if configFile != "" {
+		if err = readConfigFile(configFile, props); err != nil {
+			return err
+		}
+	}
+	if environment != "" {
+		if err = readEnvironmentProfile(app, environment, props); err != nil {
+			return err
+		}
+	}
+
+	if err = readContextParams(app, props); err != nil {
+		return err
+	}
+
 	// read secret from env var
-	props.ClientSecret = getEnvFromVars("ACP_CLIENT_SECRET")
-	props.IssuerURL = readCtxParam(app, "issuerURL")
-	props.VpcID = readCtxParam(app, "vpcID")
-	props.Version = readCtxParam(app, "version")
-	props.LoggingLevel = readCtxParam(app, "loggingLevel")
-
-	reloadInterval := readCtxParam(app, "reloadInterval")
-	if reloadInterval != "" {
-		if props.ReloadInterval, err = time.ParseDuration(reloadInterval); err != nil {
-			return fmt.Errorf("invalid reloadInterval duration %w", err)
+	if clientSecret := getEnvFromVars("ACP_CLIENT_SECRET"); clientSecret != "" {
+		props.ClientSecret = clientSecret
+	}
+	if environment != "" && props.StackName == nil {
+		props.StackName = jsii.String("CloudentityAwsAuthorizer-" + environment)
+	}
+	return nil
+}
+
+// contextAliases are flat context params mapped to the paths of nested props they set, e.g. -c syncMemorySize=512
+var contextAliases = map[string]string{
+	"authorizerMemorySize":           "authorizerLambdaSettings.memorySize",
+	"authorizerEphemeralStorageSize": "authorizerLambdaSettings.ephemeralStorageSize",
+	"authorizerTimeout":              "authorizerLambdaSettings.timeout",
+	"syncMemorySize":                 "syncLambdaSettings.memorySize",
+	"syncEphemeralStorageSize":       "syncLambdaSettings.ephemeralStorageSize",
+	"syncTimeout":                    "syncLambdaSettings.timeout",
+	// authorizerProvisionedConcurrency is an alias only when it's not an object
+	"authorizerProvisionedConcurrency":                  "authorizerProvisionedConcurrency.minCapacity",
+	"authorizerProvisionedConcurrencyMax":               "authorizerProvisionedConcurrency.maxCapacity",
+	"authorizerProvisionedConcurrencyUtilizationTarget": "authorizerProvisionedConcurrency.utilizationTarget",
+}
+
+// readContextParams decodes context params named after json tags of props,
+// values can be native JSON types from cdk.json or strings passed with -c key=value
+func readContextParams(app awscdk.App, props *authorizer.StackProps) error {
+	var (
+		params = configFile{AuthorizerProps: props.AuthorizerProps}
+		fields = map[string]reflect.Value{}
+		keys   []string
+		d      = decoder{coerceStrings: true}
+	)
+
+	collectFields(reflect.ValueOf(&params).Elem(), fields)
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	for key := range contextAliases {
+		if _, ok := fields[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		val, ok := contextValue(app, key)
+		if !ok {
+			continue
+		}
+		field, isField := fields[key]
+		if alias, ok := contextAliases[key]; ok && !(isField && isObject(val)) {
+			field = fieldByPath(reflect.ValueOf(&params).Elem(), alias)
 		}
+		d.decodeValue(val, field, key)
 	}
-	props.AnalyticsDisabled = readBoolCtxParam(app, "analyticsDisabled")
-	props.InjectContext = readBoolCtxParam(app, "injectContext")
-	props.EnforcementAllowUnknown = readBoolCtxParam(app, "enforcementAllowUnknown")
-	props.HTTPClientRootCA = readCtxParam(app, "httpClientRootCA")
-	props.HTTPClientInsecureSkipVerify = readBoolCtxParam(app, "httpClientInsecureSkipVerify")
-	props.S3BucketName = readCtxParam(app, "s3BucketName")
 
-	props.StackName = jsii.String(readCtxParam(app, "stackName"))
+	if err := d.err(); err != nil {
+		return fmt.Errorf("invalid context params %w", err)
+	}
+
+	props.AuthorizerProps = params.AuthorizerProps
+	if params.StackName != "" {
+		props.StackName = &params.StackName
+	}
 	return nil
 }
 
-func readCtxParam(app awscdk.App, key string) string {
-	val, ok := app.Node().TryGetContext(jsii.String(key)).(string)
+// fieldByPath returns a nested struct field by a dot separated path of json tags
+func fieldByPath(v reflect.Value, path string) reflect.Value {
+	for _, key := range strings.Split(path, ".") {
+		fields := map[string]reflect.Value{}
+		collectFields(v, fields)
+		v = fields[key]
+	}
+	return v
+}
+
+// readContext decodes a single context param into target, a param which is not set leaves target untouched
+func readContext(app awscdk.App, key string, target interface{}) error {
+	val, ok := contextValue(app, key)
 	if !ok {
-		return ""
+		return nil
 	}
-	return val
+
+	d := decoder{coerceStrings: true}
+	d.decodeValue(val, reflect.ValueOf(target).Elem(), key)
+	return d.err()
 }
 
-func readBoolCtxParam(app awscdk.App, key string) bool {
-	return readCtxParam(app, key) == "true"
+// contextValue returns a value of a context param, empty strings are treated as not set
+func contextValue(app awscdk.App, key string) (interface{}, bool) {
+	val := app.Node().TryGetContext(jsii.String(key))
+	if val == nil || val == "" {
+		return nil, false
+	}
+	return val, true
+}
+
+func isObject(val interface{}) bool {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		return true
+	case string:
+		return strings.HasPrefix(strings.TrimSpace(v), "{")
+	}
+	return false
 }
 
 func env() *awscdk.Environment {
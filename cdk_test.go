@@ -1,26 +1,293 @@
 package main
 
-// import (
-// 	"testing"
-
-// 	"github.com/aws/aws-cdk-go/awscdk/v2"
-// 	"github.com/aws/aws-cdk-go/awscdk/v2/assertions"
-// 	"github.com/aws/jsii-runtime-go"
-// )
-
-// example tests. To run these tests, uncomment this file along with the
-// example resource in cdk_test.go
-// func TestCdkStack(t *testing.T) {
-// 	// GIVEN
-// 	app := awscdk.NewApp(nil)
-
-// 	// WHEN
-// 	stack := NewCdkStack(app, "MyStack", nil)
-
-// 	// THEN
-// 	template := assertions.Template_FromStack(stack)
-
-// 	template.HasResourceProperties(jsii.String("AWS::SQS::Queue"), map[string]interface{}{
-// 		"VisibilityTimeout": 300,
-// 	})
-// }
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/cloudentity/awsauthorizercdk/pkg/stacks/authorizer"
+)
+
+// newTestApp creates an app with context values as set in cdk.json or passed with -c key=value
+func newTestApp(context map[string]interface{}) awscdk.App {
+	return awscdk.NewApp(&awscdk.AppProps{Context: &context})
+}
+
+func TestReadStackPropsPrecedence(t *testing.T) {
+	config := writeTestFile(t, "config.yaml", `
+stackName: authorizer-file
+clientID: file-client-id
+issuerURL: https://file.authz.cloudentity.io/example/system
+version: 2.20.0
+loggingLevel: debug
+authorizerEnv:
+  FROM_FILE: "true"
+  LAYER: file
+`)
+	t.Setenv("ACP_CLIENT_SECRET", "env-client-secret")
+
+	var (
+		app = newTestApp(map[string]interface{}{
+			"configFile": config,
+			"env":        "prod",
+			"environments": map[string]interface{}{
+				"prod": map[string]interface{}{
+					"issuerURL": "https://prod.authz.cloudentity.io/example/system",
+					"version":   "2.21.0",
+					"authorizerEnv": map[string]interface{}{
+						"FROM_PROFILE": "true",
+						"LAYER":        "profile",
+					},
+				},
+			},
+			"version": "2.22.0",
+		})
+		props = authorizer.StackProps{}
+	)
+
+	if err := readStackProps(app, &props); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name     string
+		actual   string
+		expected string
+	}{
+		{"clientID set only in the config file", props.ClientID, "file-client-id"},
+		{"loggingLevel set only in the config file", props.LoggingLevel, "debug"},
+		{"issuerURL from the profile over the config file", props.IssuerURL, "https://prod.authz.cloudentity.io/example/system"},
+		{"version from the context param over the profile and the config file", props.Version, "2.22.0"},
+		{"client secret from the env var", props.ClientSecret, "env-client-secret"},
+		{"stack name from the config file", *props.StackName, "authorizer-file"},
+		{"authorizerEnv entry from the config file", props.AuthorizerEnv["FROM_FILE"], "true"},
+		{"authorizerEnv entry from the profile", props.AuthorizerEnv["FROM_PROFILE"], "true"},
+		{"authorizerEnv entry from the profile over the config file", props.AuthorizerEnv["LAYER"], "profile"},
+	} {
+		if c.actual != c.expected {
+			t.Errorf("%s: expected %s, got %s", c.name, c.expected, c.actual)
+		}
+	}
+}
+
+func TestReadStackPropsClientSecretEnvVar(t *testing.T) {
+	t.Setenv("ACP_CLIENT_SECRET", "")
+
+	var (
+		app   = newTestApp(map[string]interface{}{"clientSecretArn": "arn:aws:secretsmanager:eu-west-1:123456789012:secret:acp-AbCdEf"})
+		props = authorizer.StackProps{}
+	)
+
+	if err := readStackProps(app, &props); err != nil {
+		t.Fatal(err)
+	}
+	if props.ClientSecret != "" {
+		t.Errorf("expected no client secret when the env var is empty, got %s", props.ClientSecret)
+	}
+	if props.ClientSecretArn != "arn:aws:secretsmanager:eu-west-1:123456789012:secret:acp-AbCdEf" {
+		t.Errorf("unexpected clientSecretArn %s", props.ClientSecretArn)
+	}
+}
+
+func TestReadStackPropsUnknownConfigFileKeys(t *testing.T) {
+	var (
+		config = writeTestFile(t, "config.json", `{"issuerUrl": "https://example.authz.cloudentity.io/example/system"}`)
+		app    = newTestApp(map[string]interface{}{"configFile": config})
+		props  = authorizer.StackProps{}
+		err    = readStackProps(app, &props)
+	)
+
+	if err == nil || err.Error() != "invalid config file "+config+" unknown key issuerUrl" {
+		t.Errorf("expected an unknown key error, got %v", err)
+	}
+}
+
+func TestReadStackPropsProfileStackName(t *testing.T) {
+	tcs := []struct {
+		name     string
+		context  map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "derived from the profile name",
+			context:  map[string]interface{}{"env": "staging"},
+			expected: "CloudentityAwsAuthorizer-staging",
+		},
+		{
+			name: "set in the profile",
+			context: map[string]interface{}{
+				"env":          "staging",
+				"environments": map[string]interface{}{"staging": map[string]interface{}{"stackName": "authorizer-staging"}},
+			},
+			expected: "authorizer-staging",
+		},
+		{
+			name:     "set with a context param",
+			context:  map[string]interface{}{"env": "staging", "stackName": "authorizer-ctx"},
+			expected: "authorizer-ctx",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, ok := tc.context["environments"]; !ok {
+				tc.context["environments"] = map[string]interface{}{"staging": map[string]interface{}{}}
+			}
+
+			props := authorizer.StackProps{}
+			if err := readStackProps(newTestApp(tc.context), &props); err != nil {
+				t.Fatal(err)
+			}
+			if props.StackName == nil || *props.StackName != tc.expected {
+				t.Errorf("expected stack name %s, got %v", tc.expected, props.StackName)
+			}
+		})
+	}
+}
+
+func TestReadContextParams(t *testing.T) {
+	tcs := []struct {
+		name    string
+		context map[string]interface{}
+		assert  func(t *testing.T, props authorizer.AuthorizerProps)
+		err     string
+	}{
+		{
+			name: "native json values",
+			context: map[string]interface{}{
+				"manuallyCreateAuthorizer": true,
+				"reloadInterval":           "2m",
+				"authorizerLambdaSettings": map[string]interface{}{"memorySize": 512, "timeout": "10s"},
+				"efsSettings":              map[string]interface{}{"availabilityZones": []interface{}{"eu-west-1a"}},
+				"authorizerEnv":            map[string]interface{}{"FEATURE_FLAG": "on"},
+			},
+			assert: func(t *testing.T, props authorizer.AuthorizerProps) {
+				if !props.ManuallyCreateAuthorizer {
+					t.Error("expected manuallyCreateAuthorizer to be set")
+				}
+				if props.ReloadInterval != 2*time.Minute {
+					t.Errorf("expected reloadInterval 2m, got %s", props.ReloadInterval)
+				}
+				if props.AuthorizerLambdaSettings.MemorySize != 512 || props.AuthorizerLambdaSettings.Timeout != 10*time.Second {
+					t.Errorf("unexpected authorizerLambdaSettings %+v", props.AuthorizerLambdaSettings)
+				}
+				if len(props.EFSSettings.AvailabilityZones) != 1 || props.EFSSettings.AvailabilityZones[0] != "eu-west-1a" {
+					t.Errorf("unexpected efsSettings.availabilityZones %v", props.EFSSettings.AvailabilityZones)
+				}
+				if props.AuthorizerEnv["FEATURE_FLAG"] != "on" {
+					t.Errorf("unexpected authorizerEnv %v", props.AuthorizerEnv)
+				}
+			},
+		},
+		{
+			name: "strings passed with -c",
+			context: map[string]interface{}{
+				"manuallyCreateAuthorizer": "true",
+				"reloadInterval":           "2m",
+				"authorizerLambdaSettings": `{"memorySize": 512, "timeout": "10s"}`,
+				"efsSettings":              `{"availabilityZones": ["eu-west-1a"]}`,
+				"authorizerEnv":            "FEATURE_FLAG=on,OTHER=1",
+			},
+			assert: func(t *testing.T, props authorizer.AuthorizerProps) {
+				if !props.ManuallyCreateAuthorizer {
+					t.Error("expected manuallyCreateAuthorizer to be set")
+				}
+				if props.ReloadInterval != 2*time.Minute {
+					t.Errorf("expected reloadInterval 2m, got %s", props.ReloadInterval)
+				}
+				if props.AuthorizerLambdaSettings.MemorySize != 512 || props.AuthorizerLambdaSettings.Timeout != 10*time.Second {
+					t.Errorf("unexpected authorizerLambdaSettings %+v", props.AuthorizerLambdaSettings)
+				}
+				if len(props.EFSSettings.AvailabilityZones) != 1 || props.EFSSettings.AvailabilityZones[0] != "eu-west-1a" {
+					t.Errorf("unexpected efsSettings.availabilityZones %v", props.EFSSettings.AvailabilityZones)
+				}
+				if props.AuthorizerEnv["FEATURE_FLAG"] != "on" || props.AuthorizerEnv["OTHER"] != "1" {
+					t.Errorf("unexpected authorizerEnv %v", props.AuthorizerEnv)
+				}
+			},
+		},
+		{
+			name: "empty strings are not set",
+			context: map[string]interface{}{
+				"manuallyCreateAuthorizer": "",
+				"reloadInterval":           "",
+			},
+			assert: func(t *testing.T, props authorizer.AuthorizerProps) {
+				if props.ManuallyCreateAuthorizer || props.ReloadInterval != 0 {
+					t.Errorf("expected unset values, got %t %s", props.ManuallyCreateAuthorizer, props.ReloadInterval)
+				}
+			},
+		},
+		{
+			name: "aliases",
+			context: map[string]interface{}{
+				"authorizerMemorySize":                              "1024",
+				"authorizerTimeout":                                 "10s",
+				"syncEphemeralStorageSize":                          1024,
+				"authorizerProvisionedConcurrency":                  "2",
+				"authorizerProvisionedConcurrencyMax":               4,
+				"authorizerProvisionedConcurrencyUtilizationTarget": "0.7",
+			},
+			assert: func(t *testing.T, props authorizer.AuthorizerProps) {
+				if props.AuthorizerLambdaSettings.MemorySize != 1024 || props.AuthorizerLambdaSettings.Timeout != 10*time.Second {
+					t.Errorf("unexpected authorizerLambdaSettings %+v", props.AuthorizerLambdaSettings)
+				}
+				if props.SyncLambdaSettings.EphemeralStorageSize != 1024 {
+					t.Errorf("unexpected syncLambdaSettings %+v", props.SyncLambdaSettings)
+				}
+				pc := props.AuthorizerProvisionedConcurrency
+				if pc.MinCapacity != 2 || pc.MaxCapacity != 4 || pc.UtilizationTarget != 0.7 {
+					t.Errorf("unexpected authorizerProvisionedConcurrency %+v", pc)
+				}
+			},
+		},
+		{
+			name: "provisioned concurrency object is not an alias",
+			context: map[string]interface{}{
+				"authorizerProvisionedConcurrency": `{"minCapacity": 1, "maxCapacity": 3}`,
+			},
+			assert: func(t *testing.T, props authorizer.AuthorizerProps) {
+				if pc := props.AuthorizerProvisionedConcurrency; pc.MinCapacity != 1 || pc.MaxCapacity != 3 {
+					t.Errorf("unexpected authorizerProvisionedConcurrency %+v", pc)
+				}
+			},
+		},
+		{
+			name: "malformed values",
+			context: map[string]interface{}{
+				"manuallyCreateAuthorizer": "yes",
+				"authorizerMemorySize":     "big",
+				"reloadInterval":           "soon",
+				"authorizerEnv":            "FEATURE_FLAG",
+				"clientID":                 123,
+			},
+			err: "invalid context params " +
+				"invalid authorizerEnv value FEATURE_FLAG, expected a map; " +
+				"invalid authorizerMemorySize value big, expected an integer; " +
+				"invalid clientID value 123, expected a string; " +
+				"invalid manuallyCreateAuthorizer value yes, expected a boolean; " +
+				"invalid reloadInterval value soon, expected a duration, e.g. 10s",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				props authorizer.StackProps
+				err   = readContextParams(newTestApp(tc.context), &props)
+			)
+
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.assert(t, props.AuthorizerProps)
+		})
+	}
+}
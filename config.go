@@ -0,0 +1,365 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/jsii-runtime-go"
+	"github.com/cloudentity/awsauthorizercdk/pkg/stacks/authorizer"
+	"gopkg.in/yaml.v3"
+)
+
+// configFile is a document with stack props, see docs/config.schema.json
+type configFile struct {
+	StackName string `json:"stackName"`
+	authorizer.AuthorizerProps
+}
+
+// readConfigFile reads stack props from a YAML or JSON file
+func readConfigFile(path string, props *authorizer.StackProps) error {
+	var (
+		err    error
+		data   []byte
+		values map[string]interface{}
+		config = configFile{AuthorizerProps: props.AuthorizerProps}
+	)
+
+	if data, err = os.ReadFile(path); err != nil {
+		return fmt.Errorf("could not read config file %w", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &values)
+	} else {
+		err = yaml.Unmarshal(data, &values)
+	}
+	if err != nil {
+		return fmt.Errorf("could not parse config file %s %w", path, err)
+	}
+
+	if err = decodeProps(values, &config); err != nil {
+		return fmt.Errorf("invalid config file %s %w", path, err)
+	}
+
+	props.AuthorizerProps = config.AuthorizerProps
+	if config.StackName != "" {
+		props.StackName = &config.StackName
+	}
+	return nil
+}
+
+// environmentProfile is a document with stack props of a single environment in cdk.json context
+type environmentProfile struct {
+	configFile
+	// Account is an AWS account the stack is deployed to
+	Account string `json:"account"`
+	// Region is an AWS region the stack is deployed to
+	Region string `json:"region"`
+	// Tags are applied to all resources of the stack
+	Tags map[string]string `json:"tags"`
+}
+
+// readEnvironmentProfile reads stack props of the environment selected with -c env=name
+// from the environments map in context, merged over shared environmentDefaults
+func readEnvironmentProfile(app awscdk.App, name string, props *authorizer.StackProps) error {
+	var (
+		environments, _ = app.Node().TryGetContext(jsii.String("environments")).(map[string]interface{})
+		defaults, _     = app.Node().TryGetContext(jsii.String("environmentDefaults")).(map[string]interface{})
+		profile         = environmentProfile{configFile: configFile{AuthorizerProps: props.AuthorizerProps}}
+	)
+
+	values, ok := environments[name].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unknown environment %s, it's not defined in environments context", name)
+	}
+
+	if err := decodeProps(defaults, &profile); err != nil {
+		return fmt.Errorf("invalid environmentDefaults %w", err)
+	}
+	if err := decodeProps(values, &profile); err != nil {
+		return fmt.Errorf("invalid environment %s %w", name, err)
+	}
+
+	props.AuthorizerProps = profile.AuthorizerProps
+	if profile.StackName != "" {
+		props.StackName = &profile.StackName
+	}
+	if profile.Account != "" || profile.Region != "" {
+		env := awscdk.Environment{}
+		if props.Env != nil {
+			env = *props.Env
+		}
+		props.Env = &env
+		if profile.Account != "" {
+			props.Env.Account = &profile.Account
+		}
+		if profile.Region != "" {
+			props.Env.Region = &profile.Region
+		}
+	}
+
+	tags := awscdk.Tags_Of(app)
+	tags.Add(jsii.String("Environment"), jsii.String(name), nil)
+	for k, v := range profile.Tags {
+		tags.Add(jsii.String(k), jsii.String(v), nil)
+	}
+	return nil
+}
+
+// decodeErrors are all the errors found while decoding props
+type decodeErrors []string
+
+func (e decodeErrors) Error() string {
+	return strings.Join(e, "; ")
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// decoder decodes generic values (as parsed from JSON or YAML) into typed props using json tags as keys
+type decoder struct {
+	// coerceStrings enables parsing of typed values from strings, as passed with -c key=value
+	coerceStrings bool
+	errs          decodeErrors
+}
+
+// decodeProps decodes values into the struct pointed by target using json tags as keys,
+// unknown keys and values of unexpected types are reported as errors
+func decodeProps(values map[string]interface{}, target interface{}) error {
+	d := decoder{}
+	d.decodeStruct(values, reflect.ValueOf(target).Elem(), "")
+	return d.err()
+}
+
+func (d *decoder) err() error {
+	if len(d.errs) > 0 {
+		return d.errs
+	}
+	return nil
+}
+
+func (d *decoder) decodeStruct(values map[string]interface{}, v reflect.Value, path string) {
+	var (
+		fields = map[string]reflect.Value{}
+		keys   = make([]string, 0, len(values))
+	)
+	collectFields(v, fields)
+
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		field, ok := fields[key]
+		if !ok {
+			d.errs = append(d.errs, fmt.Sprintf("unknown key %s", path+key))
+			continue
+		}
+		d.decodeValue(values[key], field, path+key)
+	}
+}
+
+// collectFields maps json keys to struct fields, fields of embedded structs are inlined
+func collectFields(v reflect.Value, fields map[string]reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		tag := f.Tag.Get("json")
+
+		if f.Anonymous && tag == "" {
+			collectFields(v.Field(i), fields)
+			continue
+		}
+		if tag == "" || tag == "-" || !f.IsExported() {
+			continue
+		}
+		fields[strings.Split(tag, ",")[0]] = v.Field(i)
+	}
+}
+
+func (d *decoder) decodeValue(val interface{}, v reflect.Value, path string) {
+	invalid := func(expected string) {
+		d.errs = append(d.errs, fmt.Sprintf("invalid %s value %v, expected %s", path, val, expected))
+	}
+
+	if s, ok := val.(string); ok && d.coerceStrings && v.Kind() != reflect.String {
+		coerced, ok := coerceString(s, v.Type())
+		if !ok {
+			invalid(expectedType(v.Type()))
+			return
+		}
+		val = coerced
+	}
+
+	if v.Type() == durationType {
+		s, ok := val.(string)
+		if !ok {
+			invalid(expectedType(v.Type()))
+			return
+		}
+		dur, err := time.ParseDuration(s)
+		if err != nil {
+			invalid(expectedType(v.Type()))
+			return
+		}
+		v.SetInt(int64(dur))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		s, ok := val.(string)
+		if !ok {
+			invalid(expectedType(v.Type()))
+			return
+		}
+		v.SetString(s)
+	case reflect.Bool:
+		b, ok := val.(bool)
+		if !ok {
+			invalid(expectedType(v.Type()))
+			return
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		f, ok := toFloat(val)
+		if !ok || f != math.Trunc(f) {
+			invalid(expectedType(v.Type()))
+			return
+		}
+		v.SetInt(int64(f))
+	case reflect.Float64:
+		f, ok := toFloat(val)
+		if !ok {
+			invalid(expectedType(v.Type()))
+			return
+		}
+		v.SetFloat(f)
+	case reflect.Map:
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			invalid(expectedType(v.Type()))
+			return
+		}
+		// maps are merged, so entries from previous layers are kept
+		out := reflect.MakeMapWithSize(v.Type(), len(m)+v.Len())
+		for _, k := range v.MapKeys() {
+			out.SetMapIndex(k, v.MapIndex(k))
+		}
+		for k, mv := range m {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			d.decodeValue(mv, elem, path+"."+k)
+			out.SetMapIndex(reflect.ValueOf(k), elem)
+		}
+		v.Set(out)
+	case reflect.Slice:
+		s, ok := val.([]interface{})
+		if !ok {
+			invalid(expectedType(v.Type()))
+			return
+		}
+		out := reflect.MakeSlice(v.Type(), len(s), len(s))
+		for i, sv := range s {
+			d.decodeValue(sv, out.Index(i), fmt.Sprintf("%s[%d]", path, i))
+		}
+		v.Set(out)
+	case reflect.Struct:
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			invalid(expectedType(v.Type()))
+			return
+		}
+		d.decodeStruct(m, v, path+".")
+	default:
+		invalid(v.Kind().String())
+	}
+}
+
+// coerceString parses a string into a generic value of a kind expected by t,
+// durations are left as strings, maps accept a JSON object or KEY=VALUE,... pairs
+// and lists accept a JSON array or comma separated values
+func coerceString(s string, t reflect.Type) (interface{}, bool) {
+	if t == durationType {
+		return s, true
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		return b, err == nil
+	case reflect.Int, reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		return f, err == nil
+	case reflect.Map:
+		var m map[string]interface{}
+		if strings.HasPrefix(strings.TrimSpace(s), "{") {
+			return m, json.Unmarshal([]byte(s), &m) == nil
+		}
+		m = map[string]interface{}{}
+		for _, pair := range strings.Split(s, ",") {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, false
+			}
+			m[k] = v
+		}
+		return m, true
+	case reflect.Slice:
+		var l []interface{}
+		if strings.HasPrefix(strings.TrimSpace(s), "[") {
+			return l, json.Unmarshal([]byte(s), &l) == nil
+		}
+		for _, v := range strings.Split(s, ",") {
+			l = append(l, v)
+		}
+		return l, true
+	case reflect.Struct:
+		var m map[string]interface{}
+		return m, json.Unmarshal([]byte(s), &m) == nil
+	}
+	return s, true
+}
+
+func expectedType(t reflect.Type) string {
+	if t == durationType {
+		return "a duration, e.g. 10s"
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int:
+		return "an integer"
+	case reflect.Float64:
+		return "a number"
+	case reflect.Map:
+		return "a map"
+	case reflect.Slice:
+		return "a list"
+	case reflect.Struct:
+		return "an object"
+	}
+	return t.Kind().String()
+}
+
+func toFloat(val interface{}) (float64, bool) {
+	switch n := val.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
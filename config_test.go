@@ -0,0 +1,266 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/jsii-runtime-go"
+	"github.com/cloudentity/awsauthorizercdk/pkg/stacks/authorizer"
+)
+
+// writeTestFile writes content to a file named name in a temporary directory and returns its path
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadConfigFile(t *testing.T) {
+	tcs := []struct {
+		name      string
+		file      string
+		content   string
+		stackName string
+		assert    func(t *testing.T, props authorizer.AuthorizerProps)
+		errs      []string
+	}{
+		{
+			name: "yaml",
+			file: "config.yaml",
+			content: `
+stackName: authorizer-dev
+clientID: client-id
+issuerURL: https://example.authz.cloudentity.io/example/system
+reloadInterval: 2m
+injectContext: true
+authorizerLambdaSettings:
+  memorySize: 512
+authorizerEnv:
+  FEATURE_FLAG: "on"
+efsSettings:
+  availabilityZones: [eu-west-1a, eu-west-1b]
+`,
+			stackName: "authorizer-dev",
+			assert: func(t *testing.T, props authorizer.AuthorizerProps) {
+				if props.ClientID != "client-id" {
+					t.Errorf("expected clientID client-id, got %s", props.ClientID)
+				}
+				if props.IssuerURL != "https://example.authz.cloudentity.io/example/system" {
+					t.Errorf("unexpected issuerURL %s", props.IssuerURL)
+				}
+				if props.ReloadInterval != 2*time.Minute {
+					t.Errorf("expected reloadInterval 2m, got %s", props.ReloadInterval)
+				}
+				if !props.InjectContext {
+					t.Error("expected injectContext to be set")
+				}
+				if props.AuthorizerLambdaSettings.MemorySize != 512 {
+					t.Errorf("expected authorizer memory size 512, got %d", props.AuthorizerLambdaSettings.MemorySize)
+				}
+				if props.AuthorizerEnv["FEATURE_FLAG"] != "on" {
+					t.Errorf("expected authorizerEnv FEATURE_FLAG=on, got %v", props.AuthorizerEnv)
+				}
+				if len(props.EFSSettings.AvailabilityZones) != 2 || props.EFSSettings.AvailabilityZones[1] != "eu-west-1b" {
+					t.Errorf("unexpected efsSettings.availabilityZones %v", props.EFSSettings.AvailabilityZones)
+				}
+			},
+		},
+		{
+			name:    "json",
+			file:    "config.json",
+			content: `{"clientID": "client-id", "version": "2.22.0", "syncLambdaSettings": {"timeout": "30s"}}`,
+			assert: func(t *testing.T, props authorizer.AuthorizerProps) {
+				if props.ClientID != "client-id" || props.Version != "2.22.0" {
+					t.Errorf("unexpected clientID %s or version %s", props.ClientID, props.Version)
+				}
+				if props.SyncLambdaSettings.Timeout != 30*time.Second {
+					t.Errorf("expected sync timeout 30s, got %s", props.SyncLambdaSettings.Timeout)
+				}
+			},
+		},
+		{
+			name: "unknown keys",
+			file: "config.yaml",
+			content: `
+clientId: client-id
+efsSettings:
+  throughput: elastic
+`,
+			errs: []string{"unknown key clientId", "unknown key efsSettings.throughput"},
+		},
+		{
+			name: "client secret is not read from the file",
+			file: "config.yaml",
+			content: `
+clientSecret: client-secret
+`,
+			errs: []string{"unknown key clientSecret"},
+		},
+		{
+			name: "values of unexpected types",
+			file: "config.yaml",
+			content: `
+injectContext: "yes"
+reloadInterval: 60
+authorizerLambdaSettings:
+  memorySize: 512.5
+`,
+			errs: []string{
+				"invalid authorizerLambdaSettings.memorySize value 512.5, expected an integer",
+				"invalid injectContext value yes, expected a boolean",
+				"invalid reloadInterval value 60, expected a duration, e.g. 10s",
+			},
+		},
+		{
+			name:    "malformed yaml",
+			file:    "config.yaml",
+			content: "clientID: [client-id",
+			errs:    []string{"could not parse config file"},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				path  = writeTestFile(t, tc.file, tc.content)
+				props authorizer.StackProps
+				err   = readConfigFile(path, &props)
+			)
+
+			if len(tc.errs) > 0 {
+				if err == nil {
+					t.Fatalf("expected errors %v", tc.errs)
+				}
+				for _, msg := range tc.errs {
+					if !strings.Contains(err.Error(), msg) {
+						t.Errorf("expected %q in error %q", msg, err)
+					}
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.stackName != "" && (props.StackName == nil || *props.StackName != tc.stackName) {
+				t.Errorf("expected stack name %s, got %v", tc.stackName, props.StackName)
+			}
+			tc.assert(t, props.AuthorizerProps)
+		})
+	}
+}
+
+func TestReadConfigFileMissing(t *testing.T) {
+	var props authorizer.StackProps
+
+	if err := readConfigFile(filepath.Join(t.TempDir(), "config.yaml"), &props); err == nil || !strings.Contains(err.Error(), "could not read config file") {
+		t.Errorf("expected an error reading a missing file, got %v", err)
+	}
+}
+
+func TestReadEnvironmentProfile(t *testing.T) {
+	var (
+		app = newTestApp(map[string]interface{}{
+			"environmentDefaults": map[string]interface{}{
+				"clientID":     "shared-client-id",
+				"loggingLevel": "info",
+				"tags":         map[string]interface{}{"team": "platform"},
+			},
+			"environments": map[string]interface{}{
+				"prod": map[string]interface{}{
+					"issuerURL":    "https://prod.authz.cloudentity.io/example/system",
+					"loggingLevel": "warn",
+					"account":      "210987654321",
+					"tags":         map[string]interface{}{"cost-center": "1234"},
+				},
+			},
+		})
+		stack = awscdk.NewStack(app, jsii.String("Tagged"), nil)
+		props = authorizer.StackProps{
+			StackProps: awscdk.StackProps{
+				Env: &awscdk.Environment{Account: jsii.String("123456789012"), Region: jsii.String("eu-west-1")},
+			},
+		}
+	)
+
+	if err := readEnvironmentProfile(app, "prod", &props); err != nil {
+		t.Fatal(err)
+	}
+
+	if props.ClientID != "shared-client-id" {
+		t.Errorf("expected clientID from environmentDefaults, got %s", props.ClientID)
+	}
+	if props.LoggingLevel != "warn" {
+		t.Errorf("expected loggingLevel from the profile over environmentDefaults, got %s", props.LoggingLevel)
+	}
+	if props.IssuerURL != "https://prod.authz.cloudentity.io/example/system" {
+		t.Errorf("unexpected issuerURL %s", props.IssuerURL)
+	}
+	if *props.Env.Account != "210987654321" || *props.Env.Region != "eu-west-1" {
+		t.Errorf("expected the profile account and the default region, got %s %s", *props.Env.Account, *props.Env.Region)
+	}
+
+	app.Synth(nil)
+	tags := *stack.Tags().TagValues()
+	for k, v := range map[string]string{"Environment": "prod", "team": "platform", "cost-center": "1234"} {
+		if tag, ok := tags[k]; !ok || *tag != v {
+			t.Errorf("expected tag %s=%s, got %v", k, v, tag)
+		}
+	}
+}
+
+func TestReadEnvironmentProfileWithoutEnvironment(t *testing.T) {
+	var (
+		app = newTestApp(map[string]interface{}{
+			"environments": map[string]interface{}{
+				"prod": map[string]interface{}{"region": "eu-central-1"},
+			},
+		})
+		// an environment agnostic stack
+		props = authorizer.StackProps{}
+	)
+
+	if err := readEnvironmentProfile(app, "prod", &props); err != nil {
+		t.Fatal(err)
+	}
+	if props.Env == nil || props.Env.Account != nil || props.Env.Region == nil || *props.Env.Region != "eu-central-1" {
+		t.Errorf("expected only the profile region in the stack environment, got %+v", props.Env)
+	}
+}
+
+func TestReadEnvironmentProfileErrors(t *testing.T) {
+	app := newTestApp(map[string]interface{}{
+		"environmentDefaults": map[string]interface{}{"logLevel": "info"},
+		"environments": map[string]interface{}{
+			"dev": map[string]interface{}{"issuerURL": "https://dev.authz.cloudentity.io/example/system"},
+		},
+	})
+
+	for name, expected := range map[string]string{
+		"staging": "unknown environment staging, it's not defined in environments context",
+		"dev":     "invalid environmentDefaults unknown key logLevel",
+	} {
+		var props authorizer.StackProps
+		if err := readEnvironmentProfile(app, name, &props); err == nil || err.Error() != expected {
+			t.Errorf("expected error %q for environment %s, got %v", expected, name, err)
+		}
+	}
+
+	app = newTestApp(map[string]interface{}{
+		"environments": map[string]interface{}{
+			"prod": map[string]interface{}{"vpc": "vpc-0123456789abcdef0"},
+		},
+	})
+	var props authorizer.StackProps
+	if err := readEnvironmentProfile(app, "prod", &props); err == nil || err.Error() != "invalid environment prod unknown key vpc" {
+		t.Errorf("expected an unknown key error, got %v", err)
+	}
+}
@@ -7,6 +7,7 @@ require (
 	github.com/aws/constructs-go/constructs/v10 v10.3.0
 	github.com/aws/jsii-runtime-go v1.94.0
 	github.com/go-playground/validator/v10 v10.17.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,155 @@
+package authorizer
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsappconfig"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsiam"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awslambda"
+	"github.com/aws/constructs-go/constructs/v10"
+	"github.com/aws/jsii-runtime-go"
+)
+
+const (
+	// AppConfigEnvironmentName is the name of the AppConfig environment the configuration is deployed to
+	AppConfigEnvironmentName = "live"
+	// AppConfigProfileName is the name of the hosted configuration profile the sync lambda publishes versions of
+	AppConfigProfileName = "acp-configuration"
+	// appConfigExtensionPort is the port the AppConfig Lambda extension serves configuration on
+	appConfigExtensionPort = 2772
+)
+
+// AppConfigSettings configures how the appconfig configuration store rolls out new configuration versions
+type AppConfigSettings struct {
+	// DeploymentDuration is how long a deployment takes to reach all authorizer instances, a whole number of minutes
+	DeploymentDuration time.Duration `json:"deploymentDuration" validate:"omitempty,max=24h,whole_minutes"`
+	// GrowthFactor is the percentage of authorizer instances getting the new configuration in each step of a deployment
+	GrowthFactor float64 `json:"growthFactor" validate:"omitempty,min=1,max=100"`
+	// FinalBakeTime is how long AppConfig monitors a deployment before it completes, a whole number of minutes
+	// A deployment can be stopped and rolled back until then
+	FinalBakeTime time.Duration `json:"finalBakeTime" validate:"omitempty,max=24h,whole_minutes"`
+	// ExtensionLayerArn is an ARN of the AppConfig Lambda extension layer published in the stack region for the lambda architecture,
+	// it's required with the appconfig configuration store as AWS publishes different layer versions in each region
+	ExtensionLayerArn string `json:"extensionLayerArn" validate:"required_if_top=ConfigurationStore appconfig,omitempty,lambda_layer_arn"`
+}
+
+// appConfigStore is the configuration deployed as AppConfig hosted configuration versions,
+// the authorizer lambda reads it from the AppConfig Lambda extension
+type appConfigStore struct {
+	application awsappconfig.CfnApplication
+	environment awsappconfig.CfnEnvironment
+	profile     awsappconfig.CfnConfigurationProfile
+	strategy    awsappconfig.CfnDeploymentStrategy
+	props       AuthorizerProps
+}
+
+func (s appConfigStore) setEnv(env map[string]*string) {
+	env["AWS_APPCONFIG_APPLICATION_ID"] = s.application.AttrApplicationId()
+	env["AWS_APPCONFIG_ENVIRONMENT_ID"] = s.environment.AttrId()
+	env["AWS_APPCONFIG_CONFIGURATION_PROFILE_ID"] = s.profile.AttrConfigurationProfileId()
+	env["AWS_APPCONFIG_DEPLOYMENT_STRATEGY_ID"] = s.strategy.AttrId()
+}
+
+// setReaderEnv configures the extension layer polling AppConfig
+func (s appConfigStore) setReaderEnv(env map[string]*string) {
+	poll := int(s.props.ReloadInterval.Seconds())
+	if poll < 1 {
+		poll = 1
+	}
+	env["AWS_APPCONFIG_EXTENSION_POLL_INTERVAL_SECONDS"] = jsii.String(strconv.Itoa(poll))
+	env["AWS_APPCONFIG_EXTENSION_HTTP_PORT"] = jsii.String(strconv.Itoa(appConfigExtensionPort))
+}
+
+func (s appConfigStore) attach(props *awslambda.FunctionProps) {}
+
+// addReader adds the extension layer which polls AppConfig and serves the deployed configuration locally
+func (s appConfigStore) addReader(lambda awslambda.Function) {
+	lambda.AddLayers(awslambda.LayerVersion_FromLayerVersionArn(lambda, jsii.String("AppConfigExtensionLayer"), jsii.String(s.props.AppConfigSettings.ExtensionLayerArn)))
+	lambda.AddToRolePolicy(awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
+		Actions: jsii.Strings("appconfig:StartConfigurationSession", "appconfig:GetLatestConfiguration"),
+		Resources: jsii.Strings(*s.arn(lambda, jsii.Sprintf("application/%s/environment/%s/configuration/%s",
+			*s.application.AttrApplicationId(), *s.environment.AttrId(), *s.profile.AttrConfigurationProfileId()))),
+	}))
+}
+
+// addWriter allows the sync lambda to publish hosted configuration versions and deploy them
+func (s appConfigStore) addWriter(lambda awslambda.Function) {
+	var (
+		application = *s.application.AttrApplicationId()
+		environment = *s.environment.AttrId()
+		profile     = *s.profile.AttrConfigurationProfileId()
+	)
+
+	lambda.AddToRolePolicy(awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
+		Actions: jsii.Strings(
+			"appconfig:CreateHostedConfigurationVersion",
+			"appconfig:GetHostedConfigurationVersion",
+			"appconfig:ListHostedConfigurationVersions",
+		),
+		Resources: jsii.Strings(
+			*s.arn(lambda, jsii.Sprintf("application/%s/configurationprofile/%s", application, profile)),
+			*s.arn(lambda, jsii.Sprintf("application/%s/configurationprofile/%s/hostedconfigurationversion/*", application, profile)),
+		),
+	}))
+	lambda.AddToRolePolicy(awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
+		Actions: jsii.Strings(
+			"appconfig:StartDeployment",
+			"appconfig:GetDeployment",
+			"appconfig:ListDeployments",
+			"appconfig:StopDeployment",
+		),
+		Resources: jsii.Strings(
+			*s.arn(lambda, jsii.Sprintf("application/%s/environment/%s", application, environment)),
+			*s.arn(lambda, jsii.Sprintf("application/%s/environment/%s/deployment/*", application, environment)),
+			*s.arn(lambda, jsii.Sprintf("application/%s/configurationprofile/%s", application, profile)),
+			*s.arn(lambda, jsii.Sprintf("deploymentstrategy/%s", *s.strategy.AttrId())),
+		),
+	}))
+}
+
+func (s appConfigStore) arn(scope constructs.Construct, resource *string) *string {
+	return awscdk.Stack_Of(scope).FormatArn(&awscdk.ArnComponents{
+		Service:   jsii.String("appconfig"),
+		Resource:  resource,
+		ArnFormat: awscdk.ArnFormat_NO_RESOURCE_NAME,
+	})
+}
+
+// createAppConfigStore creates the AppConfig application with an environment, a hosted configuration profile
+// and a deployment strategy the sync lambda deploys new configuration versions with
+func createAppConfigStore(scope constructs.Construct, props AuthorizerProps) appConfigStore {
+	var (
+		s        = appConfigStore{props: props}
+		settings = props.AppConfigSettings
+	)
+
+	s.application = awsappconfig.NewCfnApplication(scope, jsii.String("AuthorizerConfigurationApplication"), override(props.Overrides.AppConfigApplication, &awsappconfig.CfnApplicationProps{
+		Name:        jsii.Sprintf("%s-authorizer", *awscdk.Stack_Of(scope).StackName()),
+		Description: jsii.String("Cloudentity ACP configuration of the authorizer lambda"),
+	}))
+
+	s.environment = awsappconfig.NewCfnEnvironment(scope, jsii.String("AuthorizerConfigurationEnvironment"), override(props.Overrides.AppConfigEnvironment, &awsappconfig.CfnEnvironmentProps{
+		ApplicationId: s.application.AttrApplicationId(),
+		Name:          jsii.String(AppConfigEnvironmentName),
+	}))
+
+	s.profile = awsappconfig.NewCfnConfigurationProfile(scope, jsii.String("AuthorizerConfigurationProfile"), override(props.Overrides.AppConfigProfile, &awsappconfig.CfnConfigurationProfileProps{
+		ApplicationId: s.application.AttrApplicationId(),
+		Name:          jsii.String(AppConfigProfileName),
+		LocationUri:   jsii.String("hosted"),
+		Type:          jsii.String("AWS.Freeform"),
+	}))
+
+	s.strategy = awsappconfig.NewCfnDeploymentStrategy(scope, jsii.String("AuthorizerConfigurationDeploymentStrategy"), override(props.Overrides.AppConfigDeploymentStrategy, &awsappconfig.CfnDeploymentStrategyProps{
+		Name:                        jsii.Sprintf("%s-authorizer", *awscdk.Stack_Of(scope).StackName()),
+		DeploymentDurationInMinutes: jsii.Number(settings.DeploymentDuration.Minutes()),
+		GrowthFactor:                jsii.Number(settings.GrowthFactor),
+		FinalBakeTimeInMinutes:      jsii.Number(settings.FinalBakeTime.Minutes()),
+		GrowthType:                  jsii.String("LINEAR"),
+		ReplicateTo:                 jsii.String("NONE"),
+	}))
+
+	return s
+}
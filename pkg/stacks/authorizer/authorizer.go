@@ -0,0 +1,166 @@
+package authorizer
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsappconfig"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awscodedeploy"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsdynamodb"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsec2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsefs"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsevents"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awslambda"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awss3"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awssecretsmanager"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awssqs"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsssm"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsstepfunctions"
+	"github.com/aws/constructs-go/constructs/v10"
+	"github.com/aws/jsii-runtime-go"
+)
+
+const (
+	EfsApPath    = "/ceauthconfig"
+	EfsMountPath = "/mnt" + EfsApPath
+
+	EventBridgeTriggerIntervalMinutes = 1
+)
+
+// Authorizer is a construct with the authorizer and sync lambdas and all the resources they need
+type Authorizer struct {
+	// Construct is the scope all the resources are created in
+	Construct constructs.Construct
+	// AuthorizerLambda is the lambda function authorizing API Gateway requests
+	AuthorizerLambda awslambda.Function
+	// AuthorizerAlias is the alias of the published authorizer lambda version,
+	// nil when neither AuthorizerProvisionedConcurrency nor AuthorizerDeploymentConfig is configured
+	AuthorizerAlias awslambda.Alias
+	// AuthorizerDeploymentGroup is the CodeDeploy deployment group shifting traffic of the AuthorizerAlias,
+	// nil when AuthorizerDeploymentConfig is not set
+	AuthorizerDeploymentGroup awscodedeploy.LambdaDeploymentGroup
+	// SyncLambda is the lambda function syncing configuration from ACP
+	SyncLambda awslambda.Function
+	// RotationLambda is the lambda function rotating the client secret, nil when RotateClientSecret is not set
+	RotationLambda awslambda.Function
+	// ClientSecret is the Secrets Manager secret holding the client secret, nil when the client secret is passed inline
+	ClientSecret awssecretsmanager.ISecret
+	// Vpc is the VPC the lambdas are attached to, nil when ConfigurationStore is not efs
+	Vpc awsec2.IVpc
+	// FileSystem is the EFS file system storing configuration, imported when EFSFileSystemID is set,
+	// nil when ConfigurationStore is not efs
+	FileSystem awsefs.IFileSystem
+	// AccessPoint is the EFS access point mounted by the lambdas, imported when EFSAccessPointArn is set,
+	// nil when ConfigurationStore is not efs
+	AccessPoint awsefs.IAccessPoint
+	// ConfigurationBucket is the versioned S3 bucket storing configuration, nil when ConfigurationStore is not s3
+	ConfigurationBucket awss3.Bucket
+	// ConfigurationTable is the DynamoDB table storing configuration, nil when ConfigurationStore is not dynamodb
+	ConfigurationTable awsdynamodb.Table
+	// AppConfigApplication, AppConfigEnvironment, AppConfigProfile and AppConfigDeploymentStrategy
+	// are the AppConfig resources the configuration is deployed with, nil when ConfigurationStore is not appconfig
+	AppConfigApplication        awsappconfig.CfnApplication
+	AppConfigEnvironment        awsappconfig.CfnEnvironment
+	AppConfigProfile            awsappconfig.CfnConfigurationProfile
+	AppConfigDeploymentStrategy awsappconfig.CfnDeploymentStrategy
+	// RootCAParameter is the SSM parameter holding the root CA, imported when HTTPClientRootCASSMParameter is set
+	// or created when HTTPClientRootCA doesn't fit in the lambda environments, nil otherwise
+	RootCAParameter awsssm.IStringParameter
+	// SyncRule is the EventBridge rule triggering the sync, either directly or through the SyncLooper state machine
+	SyncRule awsevents.Rule
+	// SyncLooper is the state machine triggering the sync lambda in sub-minute intervals,
+	// nil when ReloadInterval is one minute or longer and the rule triggers the sync lambda directly
+	SyncLooper awsstepfunctions.StateMachine
+	// SyncQueue is the queue with delayed messages triggering the sync lambda, nil when there's no SyncLooper
+	SyncQueue awssqs.Queue
+	// SyncDeadLetterQueue is the dead letter queue of the SyncQueue, nil when there's no SyncLooper
+	SyncDeadLetterQueue awssqs.Queue
+}
+
+// NewAuthorizer creates the authorizer in any scope, e.g. an existing or a nested stack
+func NewAuthorizer(scope constructs.Construct, id string, props AuthorizerProps) (Authorizer, error) {
+	if err := prepareProps(&props); err != nil {
+		return Authorizer{}, err
+	}
+
+	return buildAuthorizer(constructs.NewConstruct(scope, &id), props), nil
+}
+
+// prepareProps sets defaults, validates props and reads the root CA file
+func prepareProps(props *AuthorizerProps) error {
+	setDefaultProps(props)
+
+	if err := validateProps(*props); err != nil {
+		return fmt.Errorf("invalid authorizer props %w", err)
+	}
+	return readRootCAFile(props)
+}
+
+func buildAuthorizer(scope constructs.Construct, props AuthorizerProps) Authorizer {
+	var (
+		a       = Authorizer{Construct: scope}
+		trigger syncTrigger
+		ca      = newRootCA(scope, props)
+		store   configurationStore
+	)
+
+	switch props.ConfigurationStore {
+	case ConfigurationStoreS3:
+		a.ConfigurationBucket = createConfigurationBucket(scope, props)
+		store = s3Store{bucket: a.ConfigurationBucket}
+	case ConfigurationStoreDynamoDB:
+		a.ConfigurationTable = createConfigurationTable(scope, props)
+		store = dynamoDBStore{table: a.ConfigurationTable}
+	case ConfigurationStoreAppConfig:
+		appConfig := createAppConfigStore(scope, props)
+		a.AppConfigApplication = appConfig.application
+		a.AppConfigEnvironment = appConfig.environment
+		a.AppConfigProfile = appConfig.profile
+		a.AppConfigDeploymentStrategy = appConfig.strategy
+		store = appConfig
+	default:
+		a.Vpc = getVpc(scope, props)
+		a.FileSystem, a.AccessPoint = getEFSWithAccessPoint(scope, a.Vpc, props)
+		store = efsStore{vpc: a.Vpc, subnets: efsSubnets(props.EFSSettings), accessPoint: a.AccessPoint}
+	}
+	a.ClientSecret = getClientSecret(scope, props)
+	a.AuthorizerLambda = createAuthorizerLambda(scope, store, a.ClientSecret, ca, props)
+	a.AuthorizerAlias = createAuthorizerAlias(scope, a.AuthorizerLambda, props)
+	if props.AuthorizerDeploymentConfig != "" {
+		a.AuthorizerDeploymentGroup = createAuthorizerDeploymentGroup(scope, a.AuthorizerAlias, props)
+	}
+	a.SyncLambda = createSyncLambda(scope, a.AuthorizerHandler(), store, a.ClientSecret, ca, props)
+
+	trigger = triggerLambdaInIntervals(scope, a.SyncLambda, props)
+	a.SyncRule = trigger.rule
+	a.SyncLooper = trigger.stateMachine
+	a.SyncQueue = trigger.queue
+	a.SyncDeadLetterQueue = trigger.deadLetterQueue
+
+	if props.RotateClientSecret {
+		a.RotationLambda = rotateClientSecret(scope, a.Vpc, a.ClientSecret, ca, props)
+	}
+	a.RootCAParameter = ca.parameter
+
+	return a
+}
+
+// AuthorizerHandler returns the function API Gateway authorizers should invoke,
+// the alias when it's created or the authorizer lambda otherwise
+func (a Authorizer) AuthorizerHandler() awslambda.IFunction {
+	if a.AuthorizerAlias != nil {
+		return a.AuthorizerAlias
+	}
+	return a.AuthorizerLambda
+}
+
+func getVpc(scope constructs.Construct, props AuthorizerProps) awsec2.IVpc {
+	if props.VpcID != "" {
+		return awsec2.Vpc_FromLookup(scope, jsii.String("VPC"), &awsec2.VpcLookupOptions{
+			VpcId: jsii.String(props.VpcID),
+		})
+	}
+	vpc := awsec2.NewVpc(scope, jsii.String("VPC"), override(props.Overrides.Vpc, &awsec2.VpcProps{}))
+	vpc.ApplyRemovalPolicy(awscdk.RemovalPolicy_DESTROY)
+	return vpc
+}
@@ -0,0 +1,61 @@
+package authorizer
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsapplicationautoscaling"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awslambda"
+	"github.com/aws/constructs-go/constructs/v10"
+	"github.com/aws/jsii-runtime-go"
+)
+
+// AuthorizerAliasName is the name of the authorizer lambda alias API Gateway is wired to
+const AuthorizerAliasName = "live"
+
+// createAuthorizerAlias publishes the authorizer lambda version behind an alias with provisioned concurrency
+// or shifted with CodeDeploy, it returns nil when neither of them is configured
+func createAuthorizerAlias(scope constructs.Construct, lambda awslambda.Function, props AuthorizerProps) awslambda.Alias {
+	var (
+		alias   awslambda.Alias
+		scaling awslambda.IScalableFunctionAttribute
+		pc      = props.AuthorizerProvisionedConcurrency
+	)
+
+	if pc.MinCapacity == 0 && props.AuthorizerDeploymentConfig == "" {
+		return nil
+	}
+
+	aliasProps := &awslambda.AliasProps{
+		AliasName: jsii.String(AuthorizerAliasName),
+		Version:   lambda.CurrentVersion(),
+	}
+	if pc.MinCapacity != 0 {
+		aliasProps.ProvisionedConcurrentExecutions = jsii.Number(pc.MinCapacity)
+	}
+	alias = awslambda.NewAlias(scope, jsii.String("AuthorizerLambdaAlias"), override(props.Overrides.AuthorizerAlias, aliasProps))
+
+	if pc.MaxCapacity == 0 {
+		return alias
+	}
+
+	scaling = alias.AddAutoScaling(&awslambda.AutoScalingOptions{
+		MinCapacity: jsii.Number(pc.MinCapacity),
+		MaxCapacity: jsii.Number(pc.MaxCapacity),
+	})
+
+	if pc.UtilizationTarget != 0 {
+		scaling.ScaleOnUtilization(&awslambda.UtilizationScalingOptions{
+			UtilizationTarget: jsii.Number(pc.UtilizationTarget),
+		})
+	}
+
+	for i, schedule := range pc.Schedules {
+		scaling.ScaleOnSchedule(jsii.String(fmt.Sprintf("ProvisionedConcurrencySchedule%d", i)), &awsapplicationautoscaling.ScalingSchedule{
+			Schedule:    awsapplicationautoscaling.Schedule_Expression(jsii.String(schedule.Expression)),
+			MinCapacity: jsii.Number(schedule.MinCapacity),
+			MaxCapacity: jsii.Number(schedule.MaxCapacity),
+		})
+	}
+
+	return alias
+}
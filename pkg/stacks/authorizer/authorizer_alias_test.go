@@ -0,0 +1,112 @@
+package authorizer
+
+import (
+	"testing"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2/assertions"
+	"github.com/aws/jsii-runtime-go"
+)
+
+func TestAuthorizerAlias(t *testing.T) {
+	tcs := []struct {
+		name      string
+		props     func(*AuthorizerProps)
+		aliases   int
+		targets   int
+		policies  int
+		schedules int
+	}{
+		{
+			name:  "no alias",
+			props: func(p *AuthorizerProps) {},
+		},
+		{
+			name: "provisioned concurrency",
+			props: func(p *AuthorizerProps) {
+				p.AuthorizerProvisionedConcurrency.MinCapacity = 2
+			},
+			aliases: 1,
+		},
+		{
+			name: "auto scaling on utilization",
+			props: func(p *AuthorizerProps) {
+				p.AuthorizerProvisionedConcurrency = ProvisionedConcurrency{MinCapacity: 1, MaxCapacity: 5, UtilizationTarget: 0.9}
+			},
+			aliases:  1,
+			targets:  1,
+			policies: 1,
+		},
+		{
+			name: "scheduled scaling",
+			props: func(p *AuthorizerProps) {
+				p.AuthorizerProvisionedConcurrency = ProvisionedConcurrency{
+					MinCapacity: 1,
+					MaxCapacity: 10,
+					Schedules: []ProvisionedConcurrencySchedule{
+						{Expression: "cron(0 8 ? * MON-FRI *)", MinCapacity: 5, MaxCapacity: 10},
+						{Expression: "cron(0 18 ? * MON-FRI *)", MinCapacity: 0, MaxCapacity: 1},
+					},
+				}
+			},
+			aliases:   1,
+			targets:   1,
+			schedules: 2,
+		},
+		{
+			name: "gradual rollout",
+			props: func(p *AuthorizerProps) {
+				p.AuthorizerDeploymentConfig = "Canary10Percent5Minutes"
+			},
+			aliases: 1,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			props := testProps()
+			tc.props(&props.AuthorizerProps)
+
+			template := synthTemplate(t, props)
+			template.ResourceCountIs(jsii.String("AWS::Lambda::Alias"), jsii.Number(tc.aliases))
+			template.ResourceCountIs(jsii.String("AWS::ApplicationAutoScaling::ScalableTarget"), jsii.Number(tc.targets))
+			template.ResourceCountIs(jsii.String("AWS::ApplicationAutoScaling::ScalingPolicy"), jsii.Number(tc.policies))
+
+			pc := props.AuthorizerProvisionedConcurrency
+			if pc.MinCapacity > 0 {
+				template.HasResourceProperties(jsii.String("AWS::Lambda::Alias"), map[string]interface{}{
+					"Name": AuthorizerAliasName,
+					"ProvisionedConcurrencyConfig": map[string]interface{}{
+						"ProvisionedConcurrentExecutions": pc.MinCapacity,
+					},
+				})
+			}
+			if tc.targets > 0 {
+				scheduled := assertions.Match_Absent()
+				if tc.schedules > 0 {
+					scheduled = assertions.Match_ArrayWith(&[]interface{}{
+						assertions.Match_ObjectLike(&map[string]interface{}{
+							"Schedule": pc.Schedules[0].Expression,
+							"ScalableTargetAction": map[string]interface{}{
+								"MinCapacity": pc.Schedules[0].MinCapacity,
+								"MaxCapacity": pc.Schedules[0].MaxCapacity,
+							},
+						}),
+					})
+				}
+				template.HasResourceProperties(jsii.String("AWS::ApplicationAutoScaling::ScalableTarget"), map[string]interface{}{
+					"MinCapacity":       pc.MinCapacity,
+					"MaxCapacity":       pc.MaxCapacity,
+					"ScheduledActions":  scheduled,
+					"ScalableDimension": "lambda:function:ProvisionedConcurrency",
+				})
+			}
+			if tc.policies > 0 {
+				template.HasResourceProperties(jsii.String("AWS::ApplicationAutoScaling::ScalingPolicy"), map[string]interface{}{
+					"TargetTrackingScalingPolicyConfiguration": assertions.Match_ObjectLike(&map[string]interface{}{
+						"TargetValue": pc.UtilizationTarget,
+					}),
+				})
+			}
+		})
+	}
+}
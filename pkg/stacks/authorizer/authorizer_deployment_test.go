@@ -0,0 +1,92 @@
+package authorizer
+
+import (
+	"testing"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2/assertions"
+	"github.com/aws/jsii-runtime-go"
+)
+
+func TestAuthorizerDeploymentGroup(t *testing.T) {
+	tcs := []struct {
+		name   string
+		config string
+		groups int
+		alarms int
+	}{
+		{
+			name: "no gradual rollout",
+		},
+		{
+			name:   "canary",
+			config: "Canary10Percent5Minutes",
+			groups: 1,
+			alarms: 2,
+		},
+		{
+			name:   "linear",
+			config: "Linear10PercentEvery1Minute",
+			groups: 1,
+			alarms: 2,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			props := testProps()
+			props.AuthorizerDeploymentConfig = tc.config
+
+			template := synthTemplate(t, props)
+			template.ResourceCountIs(jsii.String("AWS::CodeDeploy::DeploymentGroup"), jsii.Number(tc.groups))
+			template.ResourceCountIs(jsii.String("AWS::CloudWatch::Alarm"), jsii.Number(tc.alarms))
+
+			if tc.groups == 0 {
+				return
+			}
+
+			template.HasResourceProperties(jsii.String("AWS::CodeDeploy::Application"), map[string]interface{}{
+				"ComputePlatform": "Lambda",
+			})
+			template.HasResourceProperties(jsii.String("AWS::CodeDeploy::DeploymentGroup"), map[string]interface{}{
+				"DeploymentConfigName": "CodeDeployDefault.Lambda" + tc.config,
+				"AutoRollbackConfiguration": map[string]interface{}{
+					"Enabled": true,
+					"Events":  []interface{}{"DEPLOYMENT_FAILURE", "DEPLOYMENT_STOP_ON_REQUEST", "DEPLOYMENT_STOP_ON_ALARM"},
+				},
+				"AlarmConfiguration": map[string]interface{}{
+					"Enabled": true,
+					"Alarms": []interface{}{
+						map[string]interface{}{"Name": map[string]interface{}{"Ref": assertions.Match_StringLikeRegexp(jsii.String("AuthorizerErrorsAlarm"))}},
+						map[string]interface{}{"Name": map[string]interface{}{"Ref": assertions.Match_StringLikeRegexp(jsii.String("AuthorizerThrottlesAlarm"))}},
+					},
+				},
+			})
+
+			for _, metric := range []string{"Errors", "Throttles"} {
+				template.HasResourceProperties(jsii.String("AWS::CloudWatch::Alarm"), map[string]interface{}{
+					"Namespace":  "AWS/Lambda",
+					"MetricName": metric,
+					"Dimensions": assertions.Match_ArrayWith(&[]interface{}{
+						map[string]interface{}{
+							"Name":  "Resource",
+							"Value": map[string]interface{}{"Fn::Join": []interface{}{"", []interface{}{assertions.Match_AnyValue(), ":live"}}},
+						},
+					}),
+					"Threshold":        1,
+					"TreatMissingData": "notBreaching",
+				})
+			}
+
+			// the live alias is shifted to new versions by the deployment group
+			template.HasResource(jsii.String("AWS::Lambda::Alias"), map[string]interface{}{
+				"Properties": assertions.Match_ObjectLike(&map[string]interface{}{"Name": "live"}),
+				"UpdatePolicy": map[string]interface{}{
+					"CodeDeployLambdaAliasUpdate": map[string]interface{}{
+						"ApplicationName":     map[string]interface{}{"Ref": assertions.Match_StringLikeRegexp(jsii.String("AuthorizerDeploymentGroupApplication"))},
+						"DeploymentGroupName": map[string]interface{}{"Ref": assertions.Match_StringLikeRegexp(jsii.String("AuthorizerDeploymentGroup"))},
+					},
+				},
+			})
+		})
+	}
+}
@@ -4,50 +4,61 @@ import (
 	"strconv"
 
 	"github.com/aws/aws-cdk-go/awscdk/v2"
-	"github.com/aws/aws-cdk-go/awscdk/v2/awsec2"
-	"github.com/aws/aws-cdk-go/awscdk/v2/awsefs"
 	"github.com/aws/aws-cdk-go/awscdk/v2/awslambda"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awssecretsmanager"
+	"github.com/aws/constructs-go/constructs/v10"
 	"github.com/aws/jsii-runtime-go"
 )
 
-func createAuthorizerLambda(stack awscdk.Stack, vpc awsec2.IVpc, efsAP awsefs.AccessPoint, props StackProps) awslambda.Function {
+func createAuthorizerLambda(scope constructs.Construct, store configurationStore, clientSecret awssecretsmanager.ISecret, ca *rootCA, props AuthorizerProps) awslambda.Function {
 	var (
-		code    awslambda.Code
-		env     map[string]*string
-		lambda  awslambda.Function
-		memSize = 128
-		maxHeap = int(float64(memSize) * 0.75)
+		code   awslambda.Code
+		env    map[string]*string
+		lambda awslambda.Function
 	)
 
 	if props.AuthorizerZip != "" {
 		code = getLocalCode(props.AuthorizerZip)
 	} else {
-		code = getCodeFromS3(stack, props, props.S3AuthorizerPrefix+props.Version+".zip")
+		code = getCodeFromS3(scope, props, getS3FileName(props.S3AuthorizerPrefix, props))
 	}
 
 	env = map[string]*string{
-		"ACP_CLIENT_ID":                              jsii.String(props.ClientID),
-		"ACP_CLIENT_SECRET":                          jsii.String(props.ClientSecret),
-		"ACP_ISSUER_URL":                             jsii.String(props.IssuerURL),
-		"LOGGING_LEVEL":                              jsii.String(props.LoggingLevel),
-		"ANALYTICS_ENABLED":                          jsii.String(strconv.FormatBool(!props.AnalyticsDisabled)),
-		"HTTP_CLIENT_ROOT_CA":                        jsii.String(props.HTTPClientRootCA),
-		"HTTP_CLIENT_INSECURE_SKIP_VERIFY":           jsii.String(strconv.FormatBool(props.HTTPClientInsecureSkipVerify)),
-		"AWS_LOCAL_CONFIGURATION":                    jsii.String(EfsMountPath),
-		"MAX_HEAP":                                   jsii.String(strconv.Itoa(maxHeap)),
+		"ACP_CLIENT_ID":                    jsii.String(props.ClientID),
+		"ACP_ISSUER_URL":                   jsii.String(props.IssuerURL),
+		"LOGGING_LEVEL":                    jsii.String(props.LoggingLevel),
+		"ANALYTICS_ENABLED":                jsii.String(strconv.FormatBool(!props.AnalyticsDisabled)),
+		"HTTP_CLIENT_INSECURE_SKIP_VERIFY": jsii.String(strconv.FormatBool(props.HTTPClientInsecureSkipVerify)),
+		"MAX_HEAP":                         jsii.String(strconv.Itoa(props.AuthorizerLambdaSettings.maxHeap())),
 		"ENFORCEMENT_CLIENT_CERTIFICATE_HEADER_NAME": jsii.String("X-SSL-CERTIFICATE"),
+		"ENFORCEMENT_ALLOW_UNKNOWN":                  jsii.String(strconv.FormatBool(props.EnforcementAllowUnknown)),
+		"INJECT_CONTEXT":                             jsii.String(strconv.FormatBool(props.InjectContext)),
+		"RELOAD_INTERVAL":                            jsii.String(props.ReloadInterval.String()),
 	}
+	store.setEnv(env)
+	store.setReaderEnv(env)
+	mergeEnv(env, props.AuthorizerEnv)
+	setClientSecretEnv(env, clientSecret, props)
+	ca.setEnv(env)
 
-	lambda = awslambda.NewFunction(stack, jsii.String("AuthorizerLambda"), &awslambda.FunctionProps{
-		Code:        code,
-		Handler:     jsii.String("bootstrap"),
-		Runtime:     awslambda.Runtime_PROVIDED_AL2023(),
-		MemorySize:  jsii.Number(128),
-		Timeout:     awscdk.Duration_Seconds(jsii.Number(10)),
-		Environment: &env,
-		Vpc:         vpc,
-		Filesystem:  awslambda.FileSystem_FromEfsAccessPoint(efsAP, jsii.String(EfsMountPath)),
-	})
+	functionProps := &awslambda.FunctionProps{
+		Code:                 code,
+		Handler:              jsii.String("bootstrap"),
+		Runtime:              awslambda.Runtime_PROVIDED_AL2023(),
+		Architecture:         getLambdaArchitecture(props),
+		MemorySize:           jsii.Number(props.AuthorizerLambdaSettings.MemorySize),
+		Timeout:              awscdk.Duration_Seconds(jsii.Number(props.AuthorizerLambdaSettings.Timeout.Seconds())),
+		EphemeralStorageSize: props.AuthorizerLambdaSettings.ephemeralStorageSize(),
+		Environment:          &env,
+	}
+	store.attach(functionProps)
+
+	lambda = awslambda.NewFunction(scope, jsii.String("AuthorizerLambda"), override(props.Overrides.AuthorizerLambda, functionProps))
+
+	store.addReader(lambda)
+	grantClientSecretRead(clientSecret, lambda)
+	ca.grantRead(lambda, env)
+	checkEnvSize(lambda, env)
 
 	return lambda
 }
@@ -0,0 +1,110 @@
+package authorizer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2/assertions"
+	"github.com/aws/jsii-runtime-go"
+)
+
+func TestAuthorizerLambdaEnvironment(t *testing.T) {
+	tcs := []struct {
+		name  string
+		props func(*AuthorizerProps)
+		env   map[string]interface{}
+	}{
+		{
+			name:  "defaults",
+			props: func(p *AuthorizerProps) {},
+			env: map[string]interface{}{
+				"INJECT_CONTEXT":            "false",
+				"ENFORCEMENT_ALLOW_UNKNOWN": "false",
+				"RELOAD_INTERVAL":           "10s",
+			},
+		},
+		{
+			name: "configured",
+			props: func(p *AuthorizerProps) {
+				p.InjectContext = true
+				p.EnforcementAllowUnknown = true
+				p.ReloadInterval = 30 * time.Second
+			},
+			env: map[string]interface{}{
+				"INJECT_CONTEXT":            "true",
+				"ENFORCEMENT_ALLOW_UNKNOWN": "true",
+				"RELOAD_INTERVAL":           "30s",
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			props := testProps()
+			tc.props(&props.AuthorizerProps)
+
+			template := synthTemplate(t, props)
+			template.HasResourceProperties(jsii.String("AWS::Lambda::Function"), map[string]interface{}{
+				"Environment": map[string]interface{}{
+					"Variables": assertions.Match_ObjectLike(&tc.env),
+				},
+			})
+		})
+	}
+}
+
+func TestLambdaSettings(t *testing.T) {
+	type function struct {
+		memorySize       int
+		timeout          int
+		ephemeralStorage interface{}
+		maxHeap          string
+	}
+
+	tcs := []struct {
+		name       string
+		props      func(*AuthorizerProps)
+		authorizer function
+		sync       function
+	}{
+		{
+			name:       "defaults",
+			props:      func(p *AuthorizerProps) {},
+			authorizer: function{memorySize: 128, timeout: 10, ephemeralStorage: assertions.Match_Absent(), maxHeap: "96"},
+			sync:       function{memorySize: 128, timeout: 30, ephemeralStorage: assertions.Match_Absent(), maxHeap: "96"},
+		},
+		{
+			name: "configured",
+			props: func(p *AuthorizerProps) {
+				p.AuthorizerLambdaSettings = LambdaSettings{MemorySize: 1000, Timeout: 20 * time.Second, EphemeralStorageSize: 1024}
+				p.SyncLambdaSettings = LambdaSettings{MemorySize: 333, Timeout: 2 * time.Minute, EphemeralStorageSize: 2048}
+			},
+			authorizer: function{memorySize: 1000, timeout: 20, ephemeralStorage: map[string]interface{}{"Size": 1024}, maxHeap: "750"},
+			sync:       function{memorySize: 333, timeout: 120, ephemeralStorage: map[string]interface{}{"Size": 2048}, maxHeap: "249"},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			props := testProps()
+			tc.props(&props.AuthorizerProps)
+
+			template := synthTemplate(t, props)
+			for _, f := range []function{tc.authorizer, tc.sync} {
+				template.HasResourceProperties(jsii.String("AWS::Lambda::Function"), map[string]interface{}{
+					"MemorySize":       f.memorySize,
+					"Timeout":          f.timeout,
+					"EphemeralStorage": f.ephemeralStorage,
+					"Environment": map[string]interface{}{
+						"Variables": assertions.Match_ObjectLike(&map[string]interface{}{"MAX_HEAP": f.maxHeap}),
+					},
+				})
+			}
+
+			// the sync lambda consumes the queue, messages have to stay invisible until it times out
+			template.HasResourceProperties(jsii.String("AWS::SQS::Queue"), map[string]interface{}{
+				"VisibilityTimeout": tc.sync.timeout,
+			})
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package authorizer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/assertions"
+	"github.com/aws/jsii-runtime-go"
+)
+
+func TestAuthorizerResources(t *testing.T) {
+	rotationZip := filepath.Join(t.TempDir(), "aws-authorizer-rotation.zip")
+	if err := os.WriteFile(rotationZip, []byte("rotation lambda"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tcs := []struct {
+		name  string
+		props func(*AuthorizerProps)
+		// set are the fields expected to be set, all the others are expected to be nil
+		set []string
+	}{
+		{
+			name:  "defaults",
+			props: func(p *AuthorizerProps) {},
+			set: []string{
+				"AuthorizerLambda", "SyncLambda", "Vpc", "FileSystem", "AccessPoint",
+				"SyncRule", "SyncLooper", "SyncQueue", "SyncDeadLetterQueue",
+			},
+		},
+		{
+			name:  "reload interval of one minute or longer",
+			props: func(p *AuthorizerProps) { p.ReloadInterval = 5 * time.Minute },
+			set:   []string{"AuthorizerLambda", "SyncLambda", "Vpc", "FileSystem", "AccessPoint", "SyncRule"},
+		},
+		{
+			name: "s3 store",
+			props: func(p *AuthorizerProps) {
+				p.ConfigurationStore = ConfigurationStoreS3
+				p.ReloadInterval = time.Minute
+			},
+			set: []string{"AuthorizerLambda", "SyncLambda", "ConfigurationBucket", "SyncRule"},
+		},
+		{
+			name: "dynamodb store",
+			props: func(p *AuthorizerProps) {
+				p.ConfigurationStore = ConfigurationStoreDynamoDB
+				p.ReloadInterval = time.Minute
+			},
+			set: []string{"AuthorizerLambda", "SyncLambda", "ConfigurationTable", "SyncRule"},
+		},
+		{
+			name: "appconfig store",
+			props: func(p *AuthorizerProps) {
+				p.ConfigurationStore = ConfigurationStoreAppConfig
+				p.AppConfigSettings.ExtensionLayerArn = testAppConfigLayerArn
+				p.ReloadInterval = time.Minute
+			},
+			set: []string{
+				"AuthorizerLambda", "SyncLambda", "AppConfigApplication", "AppConfigEnvironment",
+				"AppConfigProfile", "AppConfigDeploymentStrategy", "SyncRule",
+			},
+		},
+		{
+			name: "optional resources",
+			props: func(p *AuthorizerProps) {
+				p.ConfigurationStore = ConfigurationStoreS3
+				p.ReloadInterval = time.Minute
+				p.AuthorizerDeploymentConfig = "Canary10Percent5Minutes"
+				p.ClientSecret = ""
+				p.CreateClientSecret = true
+				p.RotateClientSecret = true
+				p.RotationZip = rotationZip
+				p.HTTPClientRootCASSMParameter = "/pki/root-ca"
+			},
+			set: []string{
+				"AuthorizerLambda", "SyncLambda", "ConfigurationBucket", "SyncRule", "AuthorizerAlias",
+				"AuthorizerDeploymentGroup", "ClientSecret", "RotationLambda", "RootCAParameter",
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			props := testProps()
+			tc.props(&props.AuthorizerProps)
+
+			var (
+				a      = synthStack(t, props).Authorizer
+				fields = map[string]interface{}{
+					"AuthorizerLambda":            a.AuthorizerLambda,
+					"AuthorizerAlias":             a.AuthorizerAlias,
+					"AuthorizerDeploymentGroup":   a.AuthorizerDeploymentGroup,
+					"SyncLambda":                  a.SyncLambda,
+					"RotationLambda":              a.RotationLambda,
+					"ClientSecret":                a.ClientSecret,
+					"Vpc":                         a.Vpc,
+					"FileSystem":                  a.FileSystem,
+					"AccessPoint":                 a.AccessPoint,
+					"ConfigurationBucket":         a.ConfigurationBucket,
+					"ConfigurationTable":          a.ConfigurationTable,
+					"AppConfigApplication":        a.AppConfigApplication,
+					"AppConfigEnvironment":        a.AppConfigEnvironment,
+					"AppConfigProfile":            a.AppConfigProfile,
+					"AppConfigDeploymentStrategy": a.AppConfigDeploymentStrategy,
+					"RootCAParameter":             a.RootCAParameter,
+					"SyncRule":                    a.SyncRule,
+					"SyncLooper":                  a.SyncLooper,
+					"SyncQueue":                   a.SyncQueue,
+					"SyncDeadLetterQueue":         a.SyncDeadLetterQueue,
+				}
+				set = map[string]bool{}
+			)
+
+			for _, name := range tc.set {
+				set[name] = true
+			}
+			for name, field := range fields {
+				if isSet := field != nil; isSet != set[name] {
+					t.Errorf("expected %s set %v, got %v", name, set[name], isSet)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorizersInOneStack(t *testing.T) {
+	var (
+		app   = awscdk.NewApp(nil)
+		stack = awscdk.NewStack(app, jsii.String("TestStack"), nil)
+	)
+
+	for _, id := range []string{"Payments", "Orders"} {
+		props := testProps().AuthorizerProps
+		props.ConfigurationStore = ConfigurationStoreDynamoDB
+
+		if _, err := NewAuthorizer(stack, id, props); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	template := assertions.Template_FromStack(stack, nil)
+	template.ResourceCountIs(jsii.String("AWS::Lambda::Function"), jsii.Number(4))
+	template.ResourceCountIs(jsii.String("AWS::DynamoDB::Table"), jsii.Number(2))
+	template.ResourceCountIs(jsii.String("AWS::StepFunctions::StateMachine"), jsii.Number(2))
+}
@@ -5,9 +5,30 @@ import (
 	"github.com/aws/aws-cdk-go/awscdk/v2/awslambda"
 	"github.com/aws/aws-cdk-go/awscdk/v2/awss3"
 	"github.com/aws/aws-cdk-go/awscdk/v2/awss3assets"
+	"github.com/aws/constructs-go/constructs/v10"
 	"github.com/aws/jsii-runtime-go"
 )
 
+const (
+	ArchitectureX86_64 = "x86_64"
+	ArchitectureArm64  = "arm64"
+)
+
+func getLambdaArchitecture(props AuthorizerProps) awslambda.Architecture {
+	if props.Architecture == ArchitectureArm64 {
+		return awslambda.Architecture_ARM_64()
+	}
+	return awslambda.Architecture_X86_64()
+}
+
+// getS3FileName returns the name of lambda package in S3, arm64 packages have an architecture suffix
+func getS3FileName(prefix string, props AuthorizerProps) string {
+	if props.Architecture == ArchitectureArm64 {
+		return prefix + props.Version + "-" + ArchitectureArm64 + ".zip"
+	}
+	return prefix + props.Version + ".zip"
+}
+
 func getLocalCode(localPath string) awslambda.Code {
 	return awslambda.Code_FromAsset(
 		jsii.String(localPath),
@@ -15,12 +36,12 @@ func getLocalCode(localPath string) awslambda.Code {
 	)
 }
 
-func getCodeFromS3(stack awscdk.Stack, props StackProps, s3FileName string) awslambda.Code {
+func getCodeFromS3(scope constructs.Construct, props AuthorizerProps, s3FileName string) awslambda.Code {
 	return awslambda.Code_FromBucket(
 		awss3.Bucket_FromBucketName(
-			stack,
+			scope,
 			jsii.String("S3Bucket"+s3FileName),
-			jsii.String(props.S3BucketName+"-"+*stack.Region()),
+			jsii.String(props.S3BucketName+"-"+*awscdk.Stack_Of(scope).Region()),
 		),
 		jsii.String(s3FileName),
 		nil,
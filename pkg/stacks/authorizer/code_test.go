@@ -0,0 +1,52 @@
+package authorizer
+
+import (
+	"testing"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2/assertions"
+	"github.com/aws/jsii-runtime-go"
+)
+
+func TestLambdaArchitecture(t *testing.T) {
+	tcs := []struct {
+		name         string
+		architecture string
+		expected     string
+		authorizerS3 string
+		syncS3       string
+	}{
+		{
+			name:         "default",
+			expected:     "x86_64",
+			authorizerS3: "cloudentity-aws-authorizer-v2-2.22.0.zip",
+			syncS3:       "cloudentity-aws-authorizer-v2-sync-2.22.0.zip",
+		},
+		{
+			name:         "arm64",
+			architecture: ArchitectureArm64,
+			expected:     "arm64",
+			authorizerS3: "cloudentity-aws-authorizer-v2-2.22.0-arm64.zip",
+			syncS3:       "cloudentity-aws-authorizer-v2-sync-2.22.0-arm64.zip",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			props := testProps()
+			props.Architecture = tc.architecture
+
+			template := synthTemplate(t, props)
+			template.ResourcePropertiesCountIs(jsii.String("AWS::Lambda::Function"), map[string]interface{}{
+				"Architectures": []interface{}{tc.expected},
+			}, jsii.Number(2))
+
+			for _, key := range []string{tc.authorizerS3, tc.syncS3} {
+				template.HasResourceProperties(jsii.String("AWS::Lambda::Function"), map[string]interface{}{
+					"Code": assertions.Match_ObjectLike(&map[string]interface{}{
+						"S3Key": key,
+					}),
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package authorizer
+
+import (
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsdynamodb"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsec2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsefs"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awslambda"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awss3"
+	"github.com/aws/constructs-go/constructs/v10"
+	"github.com/aws/jsii-runtime-go"
+)
+
+const (
+	// ConfigurationStoreEFS keeps configuration on an EFS file system mounted by the lambdas in a VPC
+	ConfigurationStoreEFS = "efs"
+	// ConfigurationStoreS3 keeps configuration in a versioned S3 bucket, the lambdas are not attached to a VPC
+	ConfigurationStoreS3 = "s3"
+	// ConfigurationStoreDynamoDB keeps configuration in a DynamoDB table, the lambdas are not attached to a VPC
+	ConfigurationStoreDynamoDB = "dynamodb"
+	// ConfigurationStoreAppConfig deploys configuration with AppConfig, the authorizer lambda reads it
+	// from the AppConfig Lambda extension, the lambdas are not attached to a VPC
+	ConfigurationStoreAppConfig = "appconfig"
+
+	// ConfigurationTablePartitionKey, ConfigurationTableSortKey and ConfigurationTableTTLAttribute
+	// are the attributes of the configuration table, items with an expired TTL are removed by DynamoDB
+	ConfigurationTablePartitionKey = "pk"
+	ConfigurationTableSortKey      = "sk"
+	ConfigurationTableTTLAttribute = "expiresAt"
+)
+
+// configurationStore is where the sync lambda writes configuration the authorizer lambda reads
+type configurationStore interface {
+	// setEnv tells the lambda where the configuration is
+	setEnv(env map[string]*string)
+	// setReaderEnv adds the variables only the authorizer lambda needs to read the configuration
+	setReaderEnv(env map[string]*string)
+	// attach adds the network and file system settings the lambda needs to reach the store
+	attach(props *awslambda.FunctionProps)
+	// addReader grants the authorizer lambda read access and adds anything else it needs to read the configuration
+	addReader(lambda awslambda.Function)
+	// addWriter grants the sync lambda access to write the configuration
+	addWriter(lambda awslambda.Function)
+}
+
+// efsStore is the configuration mounted from the EFS access point
+type efsStore struct {
+	vpc         awsec2.IVpc
+	subnets     *awsec2.SubnetSelection
+	accessPoint awsefs.IAccessPoint
+}
+
+func (s efsStore) setEnv(env map[string]*string) {
+	env["AWS_LOCAL_CONFIGURATION"] = jsii.String(EfsMountPath)
+}
+
+func (s efsStore) setReaderEnv(env map[string]*string) {}
+
+func (s efsStore) attach(props *awslambda.FunctionProps) {
+	props.Vpc = s.vpc
+	props.VpcSubnets = s.subnets
+	props.Filesystem = awslambda.FileSystem_FromEfsAccessPoint(s.accessPoint, jsii.String(EfsMountPath))
+}
+
+// grants are not needed, attaching the access point allows the lambda to mount it
+func (s efsStore) addReader(lambda awslambda.Function) {}
+
+func (s efsStore) addWriter(lambda awslambda.Function) {}
+
+// s3Store is the configuration stored as objects in the S3 bucket
+type s3Store struct {
+	bucket awss3.Bucket
+}
+
+func (s s3Store) setEnv(env map[string]*string) {
+	env["AWS_CONFIGURATION_BUCKET"] = s.bucket.BucketName()
+}
+
+func (s s3Store) setReaderEnv(env map[string]*string) {}
+
+func (s s3Store) attach(props *awslambda.FunctionProps) {}
+
+func (s s3Store) addReader(lambda awslambda.Function) {
+	s.bucket.GrantRead(lambda, nil)
+}
+
+func (s s3Store) addWriter(lambda awslambda.Function) {
+	s.bucket.GrantReadWrite(lambda, nil)
+}
+
+// dynamoDBStore is the configuration stored as items in the DynamoDB table
+type dynamoDBStore struct {
+	table awsdynamodb.Table
+}
+
+func (s dynamoDBStore) setEnv(env map[string]*string) {
+	env["AWS_CONFIGURATION_TABLE"] = s.table.TableName()
+}
+
+func (s dynamoDBStore) setReaderEnv(env map[string]*string) {}
+
+func (s dynamoDBStore) attach(props *awslambda.FunctionProps) {}
+
+func (s dynamoDBStore) addReader(lambda awslambda.Function) {
+	s.table.GrantReadData(lambda)
+}
+
+func (s dynamoDBStore) addWriter(lambda awslambda.Function) {
+	s.table.GrantReadWriteData(lambda)
+}
+
+// createConfigurationBucket creates a versioned bucket for configuration objects,
+// previous versions are kept for a week so a bad sync can be rolled back
+func createConfigurationBucket(scope constructs.Construct, props AuthorizerProps) awss3.Bucket {
+	return awss3.NewBucket(scope, jsii.String("AuthorizerConfigurationBucket"), override(props.Overrides.ConfigurationBucket, &awss3.BucketProps{
+		Versioned:         jsii.Bool(true),
+		BlockPublicAccess: awss3.BlockPublicAccess_BLOCK_ALL(),
+		Encryption:        awss3.BucketEncryption_S3_MANAGED,
+		EnforceSSL:        jsii.Bool(true),
+		RemovalPolicy:     awscdk.RemovalPolicy_DESTROY,
+		AutoDeleteObjects: jsii.Bool(true),
+		LifecycleRules: &[]*awss3.LifecycleRule{
+			{
+				NoncurrentVersionExpiration: awscdk.Duration_Days(jsii.Number(7)),
+			},
+		},
+	}))
+}
+
+// createConfigurationTable creates a table for configuration items with point-in-time recovery,
+// the table is retained on stack removal as it keeps the history of synced configuration
+func createConfigurationTable(scope constructs.Construct, props AuthorizerProps) awsdynamodb.Table {
+	return awsdynamodb.NewTable(scope, jsii.String("AuthorizerConfigurationTable"), override(props.Overrides.ConfigurationTable, &awsdynamodb.TableProps{
+		PartitionKey: &awsdynamodb.Attribute{
+			Name: jsii.String(ConfigurationTablePartitionKey),
+			Type: awsdynamodb.AttributeType_STRING,
+		},
+		SortKey: &awsdynamodb.Attribute{
+			Name: jsii.String(ConfigurationTableSortKey),
+			Type: awsdynamodb.AttributeType_STRING,
+		},
+		BillingMode:         awsdynamodb.BillingMode_PAY_PER_REQUEST,
+		PointInTimeRecovery: jsii.Bool(true),
+		TimeToLiveAttribute: jsii.String(ConfigurationTableTTLAttribute),
+		RemovalPolicy:       awscdk.RemovalPolicy_RETAIN,
+	}))
+}
@@ -0,0 +1,127 @@
+package authorizer
+
+import (
+	"testing"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2/assertions"
+	"github.com/aws/jsii-runtime-go"
+)
+
+const testAppConfigLayerArn = "arn:aws:lambda:eu-west-1:123456789012:layer:AWS-AppConfig-Extension:1"
+
+func TestConfigurationStore(t *testing.T) {
+	tcs := []struct {
+		store     string
+		props     func(*AuthorizerProps)
+		resources map[string]int
+		// properties are expected properties of resources by type
+		properties map[string]map[string]interface{}
+		env        map[string]interface{}
+	}{
+		{
+			store: ConfigurationStoreEFS,
+			resources: map[string]int{
+				"AWS::EC2::VPC":         1,
+				"AWS::EFS::FileSystem":  1,
+				"AWS::EFS::AccessPoint": 1,
+				"AWS::S3::Bucket":       0,
+			},
+			env: map[string]interface{}{
+				"AWS_LOCAL_CONFIGURATION":  EfsMountPath,
+				"AWS_CONFIGURATION_BUCKET": assertions.Match_Absent(),
+			},
+		},
+		{
+			store: ConfigurationStoreS3,
+			resources: map[string]int{
+				"AWS::EC2::VPC":         0,
+				"AWS::EFS::FileSystem":  0,
+				"AWS::EFS::AccessPoint": 0,
+				"AWS::S3::Bucket":       1,
+			},
+			env: map[string]interface{}{
+				"AWS_LOCAL_CONFIGURATION":  assertions.Match_Absent(),
+				"AWS_CONFIGURATION_BUCKET": assertions.Match_AnyValue(),
+			},
+		},
+		{
+			store: ConfigurationStoreDynamoDB,
+			resources: map[string]int{
+				"AWS::EC2::VPC":        0,
+				"AWS::EFS::FileSystem": 0,
+				"AWS::DynamoDB::Table": 1,
+			},
+			properties: map[string]map[string]interface{}{
+				"AWS::DynamoDB::Table": {
+					"BillingMode": "PAY_PER_REQUEST",
+					"PointInTimeRecoverySpecification": map[string]interface{}{
+						"PointInTimeRecoveryEnabled": true,
+					},
+					"TimeToLiveSpecification": map[string]interface{}{
+						"AttributeName": ConfigurationTableTTLAttribute,
+						"Enabled":       true,
+					},
+				},
+			},
+			env: map[string]interface{}{
+				"AWS_LOCAL_CONFIGURATION": assertions.Match_Absent(),
+				"AWS_CONFIGURATION_TABLE": assertions.Match_AnyValue(),
+			},
+		},
+		{
+			store: ConfigurationStoreAppConfig,
+			props: func(p *AuthorizerProps) {
+				p.AppConfigSettings.ExtensionLayerArn = testAppConfigLayerArn
+			},
+			resources: map[string]int{
+				"AWS::EC2::VPC":                        0,
+				"AWS::EFS::FileSystem":                 0,
+				"AWS::AppConfig::Application":          1,
+				"AWS::AppConfig::Environment":          1,
+				"AWS::AppConfig::ConfigurationProfile": 1,
+				"AWS::AppConfig::DeploymentStrategy":   1,
+			},
+			env: map[string]interface{}{
+				"AWS_LOCAL_CONFIGURATION":                       assertions.Match_Absent(),
+				"AWS_APPCONFIG_APPLICATION_ID":                  assertions.Match_AnyValue(),
+				"AWS_APPCONFIG_ENVIRONMENT_ID":                  assertions.Match_AnyValue(),
+				"AWS_APPCONFIG_CONFIGURATION_PROFILE_ID":        assertions.Match_AnyValue(),
+				"AWS_APPCONFIG_EXTENSION_POLL_INTERVAL_SECONDS": "10",
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.store, func(t *testing.T) {
+			props := testProps()
+			props.ConfigurationStore = tc.store
+			if tc.props != nil {
+				tc.props(&props.AuthorizerProps)
+			}
+
+			template := synthTemplate(t, props)
+			for resource, count := range tc.resources {
+				template.ResourceCountIs(jsii.String(resource), jsii.Number(count))
+			}
+			for resource, properties := range tc.properties {
+				template.HasResourceProperties(jsii.String(resource), properties)
+			}
+			template.HasResourceProperties(jsii.String("AWS::Lambda::Function"), map[string]interface{}{
+				"Environment": map[string]interface{}{
+					"Variables": assertions.Match_ObjectLike(&tc.env),
+				},
+			})
+		})
+	}
+}
+
+func TestAppConfigExtensionLayer(t *testing.T) {
+	props := testProps()
+	props.ConfigurationStore = ConfigurationStoreAppConfig
+	props.AppConfigSettings.ExtensionLayerArn = testAppConfigLayerArn
+
+	template := synthTemplate(t, props)
+	template.ResourcePropertiesCountIs(jsii.String("AWS::Lambda::Function"), map[string]interface{}{
+		"Layers": []interface{}{testAppConfigLayerArn},
+	}, jsii.Number(1))
+}
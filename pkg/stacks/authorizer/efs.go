@@ -1,37 +1,242 @@
 package authorizer
 
 import (
+	"reflect"
+	"regexp"
+	"strconv"
+
 	"github.com/aws/aws-cdk-go/awscdk/v2"
 	"github.com/aws/aws-cdk-go/awscdk/v2/awsec2"
 	"github.com/aws/aws-cdk-go/awscdk/v2/awsefs"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awskms"
+	"github.com/aws/constructs-go/constructs/v10"
 	"github.com/aws/jsii-runtime-go"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	// DefaultEFSPosixID is the POSIX user and group id the lambdas access the created access point as
+	DefaultEFSPosixID = "1001"
+	// DefaultEFSPermissions are the permissions of the access point root directory created on the file system
+	DefaultEFSPermissions = "750"
+)
+
+// EFSSettings configures the EFS file system and the access point the stack creates,
+// file system settings can't be used with EFSFileSystemID and POSIX settings with EFSAccessPointArn
+type EFSSettings struct {
+	// KMSKeyArn is an ARN of a customer managed KMS key encrypting the file system, by default an AWS managed key is used
+	KMSKeyArn string `json:"kmsKeyArn" validate:"omitempty,excluded_with_top=EFSFileSystemID,kms_key_arn"`
+	// ThroughputMode is bursting (default), provisioned or elastic
+	ThroughputMode string `json:"throughputMode" validate:"omitempty,excluded_with_top=EFSFileSystemID,oneof=bursting provisioned elastic"`
+	// ProvisionedThroughput is the throughput in MiB/s when ThroughputMode is provisioned
+	ProvisionedThroughput float64 `json:"provisionedThroughput" validate:"required_if=ThroughputMode provisioned,excluded_unless=ThroughputMode provisioned,omitempty,min=1"`
+	// PerformanceMode is generalPurpose (default) or maxIO, maxIO can't be used with elastic throughput nor One Zone
+	PerformanceMode string `json:"performanceMode" validate:"omitempty,excluded_with_top=EFSFileSystemID,oneof=generalPurpose maxIO,efs_performance_mode"`
+	// TransitionToIADays is the number of days without access after which files move to the Infrequent Access storage class
+	TransitionToIADays int `json:"transitionToIADays" validate:"omitempty,excluded_with_top=EFSFileSystemID,oneof=1 7 14 30 60 90"`
+	// TransitionToPrimaryOnAccess moves files back from Infrequent Access on their first access
+	TransitionToPrimaryOnAccess bool `json:"transitionToPrimaryOnAccess" validate:"omitempty,excluded_with_top=EFSFileSystemID"`
+	// OneZone stores the file system in the single availability zone set in AvailabilityZones
+	OneZone bool `json:"oneZone" validate:"omitempty,excluded_with_top=EFSFileSystemID"`
+	// AvailabilityZones restricts the mount targets and the lambdas to the VPC subnets in these availability zones, by default all are used
+	AvailabilityZones []string `json:"availabilityZones" validate:"required_if=OneZone true,omitempty,excluded_with_top=EFSFileSystemID,one_zone,unique,dive,availability_zone"`
+	// UID and GID are the POSIX user and group ids the lambdas access the file system as, 1001 by default
+	UID string `json:"uid" validate:"omitempty,excluded_with_top=EFSAccessPointArn,posix_id"`
+	GID string `json:"gid" validate:"omitempty,excluded_with_top=EFSAccessPointArn,posix_id"`
+	// Permissions are the octal permissions of the access point root directory, 750 by default
+	Permissions string `json:"permissions" validate:"omitempty,excluded_with_top=EFSAccessPointArn,posix_permissions"`
+}
+
+var (
+	efsThroughputModes = map[string]awsefs.ThroughputMode{
+		"bursting":    awsefs.ThroughputMode_BURSTING,
+		"provisioned": awsefs.ThroughputMode_PROVISIONED,
+		"elastic":     awsefs.ThroughputMode_ELASTIC,
+	}
+	efsPerformanceModes = map[string]awsefs.PerformanceMode{
+		"generalPurpose": awsefs.PerformanceMode_GENERAL_PURPOSE,
+		"maxIO":          awsefs.PerformanceMode_MAX_IO,
+	}
+	efsLifecyclePolicies = map[int]awsefs.LifecyclePolicy{
+		1:  awsefs.LifecyclePolicy_AFTER_1_DAY,
+		7:  awsefs.LifecyclePolicy_AFTER_7_DAYS,
+		14: awsefs.LifecyclePolicy_AFTER_14_DAYS,
+		30: awsefs.LifecyclePolicy_AFTER_30_DAYS,
+		60: awsefs.LifecyclePolicy_AFTER_60_DAYS,
+		90: awsefs.LifecyclePolicy_AFTER_90_DAYS,
+	}
 )
 
-func createEFSWithAccessPoint(stack awscdk.Stack, vpc awsec2.IVpc) awsefs.AccessPoint {
+// getEFSWithAccessPoint imports the file system when EFSFileSystemID is set and the access point when EFSAccessPointArn is set,
+// anything not imported is created
+func getEFSWithAccessPoint(scope constructs.Construct, vpc awsec2.IVpc, props AuthorizerProps) (awsefs.IFileSystem, awsefs.IAccessPoint) {
+	if props.EFSFileSystemID == "" {
+		return createEFSWithAccessPoint(scope, vpc, props)
+	}
+
+	// the security group of the imported file system gets an ingress rule allowing NFS from the lambdas
+	fs := awsefs.FileSystem_FromFileSystemAttributes(scope, jsii.String("AuthorizerConfigurationFileSystem"), &awsefs.FileSystemAttributes{
+		FileSystemId:  jsii.String(props.EFSFileSystemID),
+		SecurityGroup: awsec2.SecurityGroup_FromSecurityGroupId(scope, jsii.String("EFSSecurityGroup"), jsii.String(props.EFSSecurityGroupID), nil),
+	})
+
+	if props.EFSAccessPointArn != "" {
+		return fs, awsefs.AccessPoint_FromAccessPointAttributes(scope, jsii.String("EFSAccessPoint"), &awsefs.AccessPointAttributes{
+			AccessPointArn: jsii.String(props.EFSAccessPointArn),
+			FileSystem:     fs,
+		})
+	}
 
+	// removing the access point doesn't remove the directory it created on the file system
+	opts := override(props.Overrides.AccessPoint, accessPointOptions(props.EFSSettings))
+	ap := awsefs.NewAccessPoint(scope, jsii.String("EFSAccessPoint"), &awsefs.AccessPointProps{
+		FileSystem: fs,
+		Path:       opts.Path,
+		CreateAcl:  opts.CreateAcl,
+		PosixUser:  opts.PosixUser,
+	})
+	ap.ApplyRemovalPolicy(awscdk.RemovalPolicy_DESTROY)
+
+	return fs, ap
+}
+
+func createEFSWithAccessPoint(scope constructs.Construct, vpc awsec2.IVpc, props AuthorizerProps) (awsefs.IFileSystem, awsefs.IAccessPoint) {
 	var (
-		fs awsefs.FileSystem
-		ap awsefs.AccessPoint
+		fs       awsefs.FileSystem
+		ap       awsefs.AccessPoint
+		settings = props.EFSSettings
+		fsProps  = &awsefs.FileSystemProps{
+			Vpc:           vpc,
+			RemovalPolicy: awscdk.RemovalPolicy_DESTROY,
+		}
 	)
 
-	fs = awsefs.NewFileSystem(stack, jsii.String("AuthorizerConfigurationFileSystem"), &awsefs.FileSystemProps{
-		Vpc:           vpc,
-		RemovalPolicy: awscdk.RemovalPolicy_DESTROY,
-	})
+	if settings.KMSKeyArn != "" {
+		fsProps.Encrypted = jsii.Bool(true)
+		fsProps.KmsKey = awskms.Key_FromKeyArn(scope, jsii.String("EFSKey"), jsii.String(settings.KMSKeyArn))
+	}
+	if settings.ThroughputMode != "" {
+		fsProps.ThroughputMode = efsThroughputModes[settings.ThroughputMode]
+	}
+	if settings.ProvisionedThroughput > 0 {
+		fsProps.ProvisionedThroughputPerSecond = awscdk.Size_Mebibytes(jsii.Number(settings.ProvisionedThroughput))
+	}
+	if settings.PerformanceMode != "" {
+		fsProps.PerformanceMode = efsPerformanceModes[settings.PerformanceMode]
+	}
+	if settings.TransitionToIADays != 0 {
+		fsProps.LifecyclePolicy = efsLifecyclePolicies[settings.TransitionToIADays]
+	}
+	if settings.TransitionToPrimaryOnAccess {
+		fsProps.OutOfInfrequentAccessPolicy = awsefs.OutOfInfrequentAccessPolicy_AFTER_1_ACCESS
+	}
+	fsProps.VpcSubnets = efsSubnets(settings)
+	if fsProps.VpcSubnets != nil && len(*vpc.SelectSubnets(fsProps.VpcSubnets).SubnetIds) == 0 {
+		awscdk.Annotations_Of(scope).AddError(jsii.Sprintf(
+			"the VPC has no subnets in efsSettings.availabilityZones %v, availability zone names are only known when the stack has an account and a region",
+			settings.AvailabilityZones,
+		))
+	}
+
+	fs = awsefs.NewFileSystem(scope, jsii.String("AuthorizerConfigurationFileSystem"), override(props.Overrides.FileSystem, fsProps))
+
+	// One Zone is not supported by the L2 construct in this CDK version
+	if settings.OneZone {
+		fs.Node().DefaultChild().(awsefs.CfnFileSystem).SetAvailabilityZoneName(jsii.String(settings.AvailabilityZones[0]))
+	}
 
-	ap = fs.AddAccessPoint(jsii.String("EFSAccessPoint"), &awsefs.AccessPointOptions{
+	ap = fs.AddAccessPoint(jsii.String("EFSAccessPoint"), override(props.Overrides.AccessPoint, accessPointOptions(settings)))
+	ap.ApplyRemovalPolicy(awscdk.RemovalPolicy_DESTROY)
+
+	return fs, ap
+}
+
+// efsSubnets selects the subnets in EFSSettings.AvailabilityZones, both the mount targets and the lambdas are placed in them
+// as a lambda needs a mount target in every availability zone it runs in, it returns nil when all subnets are used
+func efsSubnets(settings EFSSettings) *awsec2.SubnetSelection {
+	if len(settings.AvailabilityZones) == 0 {
+		return nil
+	}
+	// EFS allows a single mount target in an availability zone, CDK selects one subnet per zone
+	// by itself only when no selection is passed
+	return &awsec2.SubnetSelection{
+		AvailabilityZones: jsii.Strings(settings.AvailabilityZones...),
+		OnePerAz:          jsii.Bool(true),
+	}
+}
+
+func accessPointOptions(settings EFSSettings) *awsefs.AccessPointOptions {
+	var (
+		uid         = DefaultEFSPosixID
+		gid         = DefaultEFSPosixID
+		permissions = DefaultEFSPermissions
+	)
+
+	if settings.UID != "" {
+		uid = settings.UID
+	}
+	if settings.GID != "" {
+		gid = settings.GID
+	}
+	if settings.Permissions != "" {
+		permissions = settings.Permissions
+	}
+
+	return &awsefs.AccessPointOptions{
 		Path: jsii.String(EfsApPath),
 		CreateAcl: &awsefs.Acl{
-			OwnerGid:    jsii.String("1001"), // Using POSIX user and group
-			OwnerUid:    jsii.String("1001"), // it can be adjusted accordingly
-			Permissions: jsii.String("750"),
+			OwnerGid:    jsii.String(gid),
+			OwnerUid:    jsii.String(uid),
+			Permissions: jsii.String(permissions),
 		},
 		PosixUser: &awsefs.PosixUser{
-			Uid: jsii.String("1001"),
-			Gid: jsii.String("1001"),
+			Uid: jsii.String(uid),
+			Gid: jsii.String(gid),
 		},
-	})
-	ap.ApplyRemovalPolicy(awscdk.RemovalPolicy_DESTROY)
+	}
+}
+
+var (
+	kmsKeyArnRegexp        = regexp.MustCompile(`^arn:aws[a-z-]*:kms:[a-z0-9-]+:\d{12}:key/[0-9a-f-]+$`)
+	availabilityZoneRegexp = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-\d[a-z]$`)
+	posixPermissionsRegexp = regexp.MustCompile(`^[0-7]{3,4}$`)
+)
+
+// validateExcludedWithTop fails when the field is set together with the param field of AuthorizerProps,
+// it works for fields of nested structs, unlike excluded_with
+func validateExcludedWithTop(fl validator.FieldLevel) bool {
+	other := reflect.Indirect(fl.Top()).FieldByName(fl.Param())
+	return !other.IsValid() || other.IsZero() || fl.Field().IsZero()
+}
+
+func validateKMSKeyArn(fl validator.FieldLevel) bool {
+	return kmsKeyArnRegexp.MatchString(fl.Field().String())
+}
+
+// validateEFSPerformanceMode checks that maxIO is not used with elastic throughput nor One Zone
+func validateEFSPerformanceMode(fl validator.FieldLevel) bool {
+	settings, ok := fl.Parent().Interface().(EFSSettings)
+	if !ok || fl.Field().String() != "maxIO" {
+		return true
+	}
+	return settings.ThroughputMode != "elastic" && !settings.OneZone
+}
+
+// validateOneZone checks that a One Zone file system has mount targets in exactly one availability zone
+func validateOneZone(fl validator.FieldLevel) bool {
+	settings, ok := fl.Parent().Interface().(EFSSettings)
+	return !ok || !settings.OneZone || fl.Field().Len() == 1
+}
+
+func validateAvailabilityZone(fl validator.FieldLevel) bool {
+	return availabilityZoneRegexp.MatchString(fl.Field().String())
+}
+
+func validatePosixID(fl validator.FieldLevel) bool {
+	_, err := strconv.ParseUint(fl.Field().String(), 10, 32)
+	return err == nil
+}
 
-	return ap
+func validatePosixPermissions(fl validator.FieldLevel) bool {
+	return posixPermissionsRegexp.MatchString(fl.Field().String())
 }
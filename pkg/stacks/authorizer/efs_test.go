@@ -0,0 +1,154 @@
+package authorizer
+
+import (
+	"testing"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2/assertions"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsec2"
+	"github.com/aws/jsii-runtime-go"
+)
+
+func TestImportedEFS(t *testing.T) {
+	tcs := []struct {
+		name         string
+		props        func(*AuthorizerProps)
+		accessPoints int
+	}{
+		{
+			name:         "file system",
+			props:        func(p *AuthorizerProps) {},
+			accessPoints: 1,
+		},
+		{
+			name: "file system and access point",
+			props: func(p *AuthorizerProps) {
+				p.EFSAccessPointArn = "arn:aws:elasticfilesystem:eu-west-1:123456789012:access-point/fsap-0123456789abcdef0"
+				p.EFSAccessPointPath = EfsApPath
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			props := testProps()
+			props.Env = testEnv()
+			props.VpcID = "vpc-0123456789abcdef0"
+			props.EFSFileSystemID = "fs-0123456789abcdef0"
+			props.EFSSecurityGroupID = "sg-0123456789abcdef0"
+			tc.props(&props.AuthorizerProps)
+
+			template := synthTemplate(t, props)
+			template.ResourceCountIs(jsii.String("AWS::EFS::FileSystem"), jsii.Number(0))
+			template.ResourceCountIs(jsii.String("AWS::EFS::AccessPoint"), jsii.Number(tc.accessPoints))
+			template.HasResourceProperties(jsii.String("AWS::EC2::SecurityGroupIngress"), map[string]interface{}{
+				"GroupId":  "sg-0123456789abcdef0",
+				"FromPort": 2049,
+			})
+			template.HasResourceProperties(jsii.String("AWS::Lambda::Function"), map[string]interface{}{
+				"FileSystemConfigs": assertions.Match_AnyValue(),
+				"Environment": map[string]interface{}{
+					"Variables": assertions.Match_ObjectLike(&map[string]interface{}{
+						"AWS_LOCAL_CONFIGURATION": EfsMountPath,
+					}),
+				},
+			})
+		})
+	}
+}
+
+func TestEFSSettings(t *testing.T) {
+	props := testProps()
+	props.Env = testEnv()
+	props.EFSSettings = EFSSettings{
+		KMSKeyArn:                   "arn:aws:kms:eu-west-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab",
+		ThroughputMode:              "provisioned",
+		ProvisionedThroughput:       10,
+		TransitionToIADays:          30,
+		TransitionToPrimaryOnAccess: true,
+		OneZone:                     true,
+		AvailabilityZones:           []string{"eu-west-1b"},
+		UID:                         "2000",
+		GID:                         "3000",
+		Permissions:                 "700",
+	}
+
+	template := synthTemplate(t, props)
+	template.HasResourceProperties(jsii.String("AWS::EFS::FileSystem"), map[string]interface{}{
+		"AvailabilityZoneName":         "eu-west-1b",
+		"Encrypted":                    true,
+		"KmsKeyId":                     props.EFSSettings.KMSKeyArn,
+		"ThroughputMode":               "provisioned",
+		"ProvisionedThroughputInMibps": 10,
+		"LifecyclePolicies": []interface{}{
+			map[string]interface{}{"TransitionToIA": "AFTER_30_DAYS"},
+			map[string]interface{}{"TransitionToPrimaryStorageClass": "AFTER_1_ACCESS"},
+		},
+	})
+	template.ResourceCountIs(jsii.String("AWS::EFS::MountTarget"), jsii.Number(1))
+	// lambdas need a mount target in every availability zone they run in
+	template.AllResourcesProperties(jsii.String("AWS::Lambda::Function"), map[string]interface{}{
+		"VpcConfig": assertions.Match_ObjectLike(&map[string]interface{}{
+			"SubnetIds": []interface{}{
+				map[string]interface{}{"Ref": assertions.Match_StringLikeRegexp(jsii.String("^VPCPrivateSubnet2"))},
+			},
+		}),
+	})
+	template.HasResourceProperties(jsii.String("AWS::EFS::AccessPoint"), map[string]interface{}{
+		"PosixUser": map[string]interface{}{"Uid": "2000", "Gid": "3000"},
+		"RootDirectory": map[string]interface{}{
+			"CreationInfo": map[string]interface{}{"OwnerUid": "2000", "OwnerGid": "3000", "Permissions": "700"},
+			"Path":         EfsApPath,
+		},
+	})
+}
+
+func TestEFSAvailabilityZonesWithoutSubnets(t *testing.T) {
+	// availability zones of an environment agnostic stack are not known at synth
+	props := testProps()
+	props.EFSSettings.OneZone = true
+	props.EFSSettings.AvailabilityZones = []string{"eu-west-1a"}
+
+	stack := synthStack(t, props)
+	annotations := assertions.Annotations_FromStack(stack.Stack)
+	errors := annotations.FindError(jsii.String("*"), assertions.Match_StringLikeRegexp(jsii.String("the VPC has no subnets in efsSettings.availabilityZones")))
+	if len(*errors) == 0 {
+		t.Fatal("expected an error about availability zones without subnets")
+	}
+}
+
+func TestEFSAvailabilityZonesWithManySubnetGroups(t *testing.T) {
+	props := testProps()
+	props.Env = testEnv()
+	props.EFSSettings.AvailabilityZones = []string{"eu-west-1a", "eu-west-1b"}
+	props.Overrides.Vpc = func(vp *awsec2.VpcProps) {
+		vp.SubnetConfiguration = &[]*awsec2.SubnetConfiguration{
+			{Name: jsii.String("Public"), SubnetType: awsec2.SubnetType_PUBLIC},
+			{Name: jsii.String("Application"), SubnetType: awsec2.SubnetType_PRIVATE_WITH_EGRESS},
+			{Name: jsii.String("Data"), SubnetType: awsec2.SubnetType_PRIVATE_WITH_EGRESS},
+		}
+	}
+
+	template := synthTemplate(t, props)
+
+	// one mount target in each of the selected availability zones, EFS rejects a second one in the same zone
+	template.ResourceCountIs(jsii.String("AWS::EFS::MountTarget"), jsii.Number(len(props.EFSSettings.AvailabilityZones)))
+
+	mountTargets := template.FindResources(jsii.String("AWS::EFS::MountTarget"), nil)
+	subnets := map[string]bool{}
+	for _, mountTarget := range *mountTargets {
+		subnet := (*mountTarget)["Properties"].(map[string]interface{})["SubnetId"].(map[string]interface{})["Ref"].(string)
+		subnets[subnet] = true
+	}
+	if len(subnets) != len(props.EFSSettings.AvailabilityZones) {
+		t.Errorf("expected mount targets in distinct subnets, got %v", subnets)
+	}
+
+	template.ResourcePropertiesCountIs(jsii.String("AWS::Lambda::Function"), map[string]interface{}{
+		"VpcConfig": assertions.Match_ObjectLike(&map[string]interface{}{
+			"SubnetIds": []interface{}{
+				map[string]interface{}{"Ref": assertions.Match_StringLikeRegexp(jsii.String("VPCApplicationSubnet1"))},
+				map[string]interface{}{"Ref": assertions.Match_StringLikeRegexp(jsii.String("VPCApplicationSubnet2"))},
+			},
+		}),
+	}, jsii.Number(2))
+}
@@ -0,0 +1,51 @@
+package authorizer
+
+import (
+	"github.com/aws/jsii-runtime-go"
+	"github.com/go-playground/validator/v10"
+)
+
+// reservedEnvKeys are environment variables set by the stack, either wiring it together or derived from props,
+// which can't be overridden with AuthorizerEnv and SyncEnv
+var reservedEnvKeys = map[string]bool{
+	"ACP_CLIENT_ID":            true,
+	"ACP_CLIENT_SECRET":        true,
+	"ACP_CLIENT_SECRET_ARN":    true,
+	"ACP_ISSUER_URL":           true,
+	"AWS_LOCAL_CONFIGURATION":  true,
+	"AWS_CONFIGURATION_BUCKET": true,
+	"AWS_CONFIGURATION_TABLE":  true,
+
+	"AWS_APPCONFIG_APPLICATION_ID":                  true,
+	"AWS_APPCONFIG_ENVIRONMENT_ID":                  true,
+	"AWS_APPCONFIG_CONFIGURATION_PROFILE_ID":        true,
+	"AWS_APPCONFIG_DEPLOYMENT_STRATEGY_ID":          true,
+	"AWS_APPCONFIG_EXTENSION_POLL_INTERVAL_SECONDS": true,
+	"AWS_APPCONFIG_EXTENSION_HTTP_PORT":             true,
+	"AWS_AUTHORIZER_ARN":                            true,
+	"AWS_CREATE_AUTHORIZER":                         true,
+	"MAX_HEAP":                                      true,
+
+	// derived from props, they're set with the dedicated params
+	"LOGGING_LEVEL":                    true,
+	"ANALYTICS_ENABLED":                true,
+	"INJECT_CONTEXT":                   true,
+	"ENFORCEMENT_ALLOW_UNKNOWN":        true,
+	"RELOAD_INTERVAL":                  true,
+	"HTTP_CLIENT_INSECURE_SKIP_VERIFY": true,
+	"HTTP_CLIENT_ROOT_CA":              true,
+	// set instead of HTTP_CLIENT_ROOT_CA when the root CA is read from SSM or S3
+	"HTTP_CLIENT_ROOT_CA_SSM_PARAMETER": true,
+	"HTTP_CLIENT_ROOT_CA_S3_URI":        true,
+}
+
+// mergeEnv sets extra environment variables over the defaults
+func mergeEnv(env map[string]*string, extra map[string]string) {
+	for k, v := range extra {
+		env[k] = jsii.String(v)
+	}
+}
+
+func validateNotReservedEnvKey(fl validator.FieldLevel) bool {
+	return !reservedEnvKeys[fl.Field().String()]
+}
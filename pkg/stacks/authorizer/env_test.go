@@ -0,0 +1,47 @@
+package authorizer
+
+import (
+	"testing"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2/assertions"
+	"github.com/aws/jsii-runtime-go"
+)
+
+func TestExtraEnvironment(t *testing.T) {
+	props := testProps()
+	props.LoggingLevel = "debug"
+	props.AuthorizerEnv = map[string]string{
+		"FEATURE_FLAG": "on",
+		"ENFORCEMENT_CLIENT_CERTIFICATE_HEADER_NAME": "X-Client-Cert",
+	}
+	props.SyncEnv = map[string]string{
+		"SYNC_BATCH_SIZE": "100",
+	}
+
+	template := synthTemplate(t, props)
+
+	// authorizer lambda
+	template.HasResourceProperties(jsii.String("AWS::Lambda::Function"), map[string]interface{}{
+		"Environment": map[string]interface{}{
+			"Variables": assertions.Match_ObjectLike(&map[string]interface{}{
+				"LOGGING_LEVEL":   "debug",
+				"INJECT_CONTEXT":  "false",
+				"FEATURE_FLAG":    "on",
+				"SYNC_BATCH_SIZE": assertions.Match_Absent(),
+				"ENFORCEMENT_CLIENT_CERTIFICATE_HEADER_NAME": "X-Client-Cert",
+			}),
+		},
+	})
+
+	// sync lambda
+	template.HasResourceProperties(jsii.String("AWS::Lambda::Function"), map[string]interface{}{
+		"Environment": map[string]interface{}{
+			"Variables": assertions.Match_ObjectLike(&map[string]interface{}{
+				"LOGGING_LEVEL":         "debug",
+				"AWS_CREATE_AUTHORIZER": "true",
+				"SYNC_BATCH_SIZE":       "100",
+				"FEATURE_FLAG":          assertions.Match_Absent(),
+			}),
+		},
+	})
+}
@@ -0,0 +1,52 @@
+package authorizer
+
+import (
+	"testing"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/assertions"
+	"github.com/aws/jsii-runtime-go"
+)
+
+// testAvailabilityZones are returned by the availability zones lookup of stacks in the testEnv environment
+var testAvailabilityZones = []string{"eu-west-1a", "eu-west-1b", "eu-west-1c"}
+
+// testProps returns the minimal valid stack props
+func testProps() StackProps {
+	props := StackProps{}
+	props.ClientID = "client-id"
+	props.ClientSecret = "client-secret"
+	props.IssuerURL = "https://example.authz.cloudentity.io/example/system"
+	props.Version = "2.22.0"
+	return props
+}
+
+// testEnv is a concrete environment, VPC lookups need one
+func testEnv() *awscdk.Environment {
+	return &awscdk.Environment{
+		Account: jsii.String("123456789012"),
+		Region:  jsii.String("eu-west-1"),
+	}
+}
+
+// synthStack creates the stack in a new app, failing the test when props are invalid
+func synthStack(t *testing.T, props StackProps) Stack {
+	t.Helper()
+
+	app := awscdk.NewApp(&awscdk.AppProps{
+		Context: &map[string]interface{}{
+			"availability-zones:account=123456789012:region=eu-west-1": testAvailabilityZones,
+		},
+	})
+
+	stack, err := NewStack(app, "TestStack", props)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stack
+}
+
+func synthTemplate(t *testing.T, props StackProps) assertions.Template {
+	t.Helper()
+	return assertions.Template_FromStack(synthStack(t, props).Stack, nil)
+}
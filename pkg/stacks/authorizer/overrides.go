@@ -0,0 +1,72 @@
+package authorizer
+
+import (
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsappconfig"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awscodedeploy"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsdynamodb"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsec2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsefs"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsevents"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsiam"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awslambda"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awss3"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awssecretsmanager"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awssqs"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsssm"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsstepfunctions"
+)
+
+// Overrides are callbacks adjusting props of the underlying resources right before they are created
+// Each callback is called only when the given resource is created
+type Overrides struct {
+	// Vpc adjusts props of a new VPC, it's not called when VpcID is set
+	Vpc func(*awsec2.VpcProps)
+	// FileSystem adjusts props of the EFS file system
+	FileSystem func(*awsefs.FileSystemProps)
+	// AccessPoint adjusts options of the EFS access point
+	AccessPoint func(*awsefs.AccessPointOptions)
+	// ConfigurationBucket adjusts props of the configuration bucket created when ConfigurationStore is s3
+	ConfigurationBucket func(*awss3.BucketProps)
+	// ConfigurationTable adjusts props of the configuration table created when ConfigurationStore is dynamodb
+	ConfigurationTable func(*awsdynamodb.TableProps)
+	// AppConfigApplication, AppConfigEnvironment, AppConfigProfile and AppConfigDeploymentStrategy
+	// adjust props of the AppConfig resources created when ConfigurationStore is appconfig
+	AppConfigApplication        func(*awsappconfig.CfnApplicationProps)
+	AppConfigEnvironment        func(*awsappconfig.CfnEnvironmentProps)
+	AppConfigProfile            func(*awsappconfig.CfnConfigurationProfileProps)
+	AppConfigDeploymentStrategy func(*awsappconfig.CfnDeploymentStrategyProps)
+	// ClientSecret adjusts props of the client secret created when CreateClientSecret is set
+	ClientSecret func(*awssecretsmanager.SecretProps)
+	// ClientSecretRotation adjusts options of the client secret rotation schedule
+	ClientSecretRotation func(*awssecretsmanager.RotationScheduleOptions)
+	// AuthorizerLambda adjusts props of the authorizer lambda
+	AuthorizerLambda func(*awslambda.FunctionProps)
+	// AuthorizerAlias adjusts props of the authorizer lambda alias created when provisioned concurrency is configured
+	AuthorizerAlias func(*awslambda.AliasProps)
+	// AuthorizerDeploymentGroup adjusts props of the CodeDeploy deployment group created when AuthorizerDeploymentConfig is set
+	AuthorizerDeploymentGroup func(*awscodedeploy.LambdaDeploymentGroupProps)
+	// SyncLambda adjusts props of the sync lambda
+	SyncLambda func(*awslambda.FunctionProps)
+	// SyncLambdaPolicy adjusts props of the inline policy attached to the sync lambda
+	SyncLambdaPolicy func(*awsiam.PolicyProps)
+	// RotationLambda adjusts props of the client secret rotation lambda
+	RotationLambda func(*awslambda.FunctionProps)
+	// RootCAParameter adjusts props of the SSM parameter created when HTTPClientRootCA doesn't fit in the lambda environment
+	RootCAParameter func(*awsssm.StringParameterProps)
+	// SyncRule adjusts props of the EventBridge rule triggering the sync
+	SyncRule func(*awsevents.RuleProps)
+	// SyncLooper adjusts props of the state machine triggering the sync lambda in sub-minute intervals
+	SyncLooper func(*awsstepfunctions.StateMachineProps)
+	// SyncQueue adjusts props of the queue triggering the sync lambda in sub-minute intervals
+	SyncQueue func(*awssqs.QueueProps)
+	// SyncDeadLetterQueue adjusts props of the dead letter queue of the SyncQueue
+	SyncDeadLetterQueue func(*awssqs.QueueProps)
+}
+
+// override calls fn, if set, with props and returns the adjusted props
+func override[T any](fn func(*T), props *T) *T {
+	if fn != nil {
+		fn(props)
+	}
+	return props
+}
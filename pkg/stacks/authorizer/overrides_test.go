@@ -0,0 +1,135 @@
+package authorizer
+
+import (
+	"testing"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2/assertions"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsdynamodb"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsec2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsefs"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsevents"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awslambda"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awss3"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awssqs"
+	"github.com/aws/jsii-runtime-go"
+)
+
+func TestOverrides(t *testing.T) {
+	tcs := []struct {
+		name       string
+		props      func(*AuthorizerProps)
+		resource   string
+		properties map[string]interface{}
+	}{
+		{
+			name: "authorizer lambda",
+			props: func(p *AuthorizerProps) {
+				p.Overrides.AuthorizerLambda = func(fp *awslambda.FunctionProps) {
+					fp.Description = jsii.String("custom authorizer")
+				}
+			},
+			resource:   "AWS::Lambda::Function",
+			properties: map[string]interface{}{"Description": "custom authorizer"},
+		},
+		{
+			name: "sync lambda",
+			props: func(p *AuthorizerProps) {
+				p.Overrides.SyncLambda = func(fp *awslambda.FunctionProps) {
+					fp.ReservedConcurrentExecutions = jsii.Number(2)
+				}
+			},
+			resource:   "AWS::Lambda::Function",
+			properties: map[string]interface{}{"ReservedConcurrentExecutions": 2},
+		},
+		{
+			name: "vpc",
+			props: func(p *AuthorizerProps) {
+				p.Overrides.Vpc = func(vp *awsec2.VpcProps) {
+					vp.IpAddresses = awsec2.IpAddresses_Cidr(jsii.String("10.10.0.0/16"))
+				}
+			},
+			resource:   "AWS::EC2::VPC",
+			properties: map[string]interface{}{"CidrBlock": "10.10.0.0/16"},
+		},
+		{
+			name: "file system",
+			props: func(p *AuthorizerProps) {
+				p.Overrides.FileSystem = func(fp *awsefs.FileSystemProps) {
+					fp.FileSystemName = jsii.String("authorizer-configuration")
+				}
+			},
+			resource: "AWS::EFS::FileSystem",
+			properties: map[string]interface{}{
+				"FileSystemTags": []interface{}{map[string]interface{}{"Key": "Name", "Value": "authorizer-configuration"}},
+			},
+		},
+		{
+			name: "configuration bucket",
+			props: func(p *AuthorizerProps) {
+				p.ConfigurationStore = ConfigurationStoreS3
+				p.Overrides.ConfigurationBucket = func(bp *awss3.BucketProps) {
+					bp.BucketName = jsii.String("authorizer-configuration")
+				}
+			},
+			resource:   "AWS::S3::Bucket",
+			properties: map[string]interface{}{"BucketName": "authorizer-configuration"},
+		},
+		{
+			name: "configuration table",
+			props: func(p *AuthorizerProps) {
+				p.ConfigurationStore = ConfigurationStoreDynamoDB
+				p.Overrides.ConfigurationTable = func(tp *awsdynamodb.TableProps) {
+					tp.TableName = jsii.String("authorizer-configuration")
+				}
+			},
+			resource:   "AWS::DynamoDB::Table",
+			properties: map[string]interface{}{"TableName": "authorizer-configuration"},
+		},
+		{
+			name: "sync rule",
+			props: func(p *AuthorizerProps) {
+				p.Overrides.SyncRule = func(rp *awsevents.RuleProps) {
+					rp.Description = jsii.String("custom sync rule")
+				}
+			},
+			resource:   "AWS::Events::Rule",
+			properties: map[string]interface{}{"Description": "custom sync rule"},
+		},
+		{
+			name: "sync queue",
+			props: func(p *AuthorizerProps) {
+				p.Overrides.SyncQueue = func(qp *awssqs.QueueProps) {
+					qp.QueueName = jsii.String("authorizer-sync")
+				}
+			},
+			resource:   "AWS::SQS::Queue",
+			properties: map[string]interface{}{"QueueName": "authorizer-sync"},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			props := testProps()
+			tc.props(&props.AuthorizerProps)
+
+			template := synthTemplate(t, props)
+			template.HasResourceProperties(jsii.String(tc.resource), assertions.Match_ObjectLike(&tc.properties))
+		})
+	}
+}
+
+func TestOverridesOfResourcesNotCreated(t *testing.T) {
+	var (
+		props  = testProps()
+		called []string
+	)
+	props.Overrides.ConfigurationBucket = func(*awss3.BucketProps) { called = append(called, "ConfigurationBucket") }
+	props.Overrides.ConfigurationTable = func(*awsdynamodb.TableProps) { called = append(called, "ConfigurationTable") }
+	props.Overrides.AuthorizerAlias = func(*awslambda.AliasProps) { called = append(called, "AuthorizerAlias") }
+
+	synthStack(t, props)
+
+	if len(called) > 0 {
+		t.Errorf("expected no overrides of resources which are not created to be called, got %v", called)
+	}
+}
@@ -1,79 +1,451 @@
 package authorizer
 
 import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/jsii-runtime-go"
 	"github.com/go-playground/validator/v10"
 )
 
+// StackProps configures the authorizer stack
 type StackProps struct {
 	awscdk.StackProps
+	AuthorizerProps
+}
+
+// AuthorizerProps configures the authorizer construct
+type AuthorizerProps struct {
 	// SyncZip is a path to zip file with sync lambda function
-	SyncZip string
+	SyncZip string `json:"syncZip"`
 	// AuthorizerZip is a path to zip file with authorizer lambda function
-	AuthorizerZip string
+	AuthorizerZip string `json:"authorizerZip"`
 	// When ManuallyCreateAuthorizer is set to true, the stack will configure sync lambda to skip auto-binding authorizer
-	ManuallyCreateAuthorizer bool
+	ManuallyCreateAuthorizer bool `json:"manuallyCreateAuthorizer"`
 	// ClientID is a client id of the client that will be used to authenticate with ACP
-	ClientID string `validate:"required"`
+	ClientID string `json:"clientID" validate:"required"`
 	// ClientSecret is a client secret of the client that will be used to authenticate with ACP
-	ClientSecret string `validate:"required"`
+	// It's passed to the lambdas as a plain environment variable, use ClientSecretArn or CreateClientSecret to keep it out of the template
+	ClientSecret string `json:"-" validate:"required_without_all=ClientSecretArn CreateClientSecret,excluded_with=ClientSecretArn CreateClientSecret"`
+	// ClientSecretArn is a complete ARN of an existing Secrets Manager secret holding the client secret
+	ClientSecretArn string `json:"clientSecretArn" validate:"omitempty,secretsmanager_secret_arn,excluded_with=CreateClientSecret"`
+	// When CreateClientSecret is set to true, the stack creates a Secrets Manager secret for the client secret
+	// The secret value has to be set outside of the stack after deployment
+	CreateClientSecret bool `json:"createClientSecret"`
+	// When RotateClientSecret is set to true, the stack deploys a rotation lambda which rotates the client secret stored in Secrets Manager
+	RotateClientSecret bool `json:"rotateClientSecret" validate:"excluded_without_all=ClientSecretArn CreateClientSecret"`
+	// ClientSecretRotationInterval is an interval of the client secret rotation, a whole number of hours
+	ClientSecretRotationInterval time.Duration `json:"clientSecretRotationInterval" validate:"omitempty,min=4h,max=24000h,whole_hours"`
+	// RotationZip is a path to zip file with client secret rotation lambda function, it's required with RotateClientSecret
+	// as the rotation lambda package is not published to the S3 bucket with the authorizer and sync packages
+	RotationZip string `json:"rotationZip" validate:"required_if=RotateClientSecret true"`
 	// IssuerURL is an issuer url of ACP
-	IssuerURL string `validate:"required"`
+	IssuerURL string `json:"issuerURL" validate:"required,http_url"`
 	// VpcID is an id of VPC that will be used to create lambda function
-	VpcID string
-	// Version is a version of lambda function
-	Version string `validate:"required"`
+	// The lambdas are attached to a VPC only when ConfigurationStore is efs
+	VpcID string `json:"vpcID" validate:"required_with=EFSFileSystemID,omitempty,vpc_id,excluded_unless=ConfigurationStore efs"`
+	// EFSFileSystemID is an id of an existing EFS file system in the VpcID VPC to store configuration on instead of creating one
+	EFSFileSystemID string `json:"efsFileSystemID" validate:"omitempty,efs_file_system_id,excluded_unless=ConfigurationStore efs"`
+	// EFSSecurityGroupID is an id of the security group of the EFS file system mount targets,
+	// the stack allows NFS traffic from the lambdas in it
+	EFSSecurityGroupID string `json:"efsSecurityGroupID" validate:"required_with=EFSFileSystemID,omitempty,security_group_id,excluded_without_all=EFSFileSystemID"`
+	// EFSAccessPointArn is an ARN of an existing access point of the EFSFileSystemID file system,
+	// by default an access point is created
+	EFSAccessPointArn string `json:"efsAccessPointArn" validate:"omitempty,efs_access_point_arn,excluded_without_all=EFSFileSystemID"`
+	// EFSAccessPointPath is the root directory of the EFSAccessPointArn access point, it must be EfsApPath
+	// so that the lambdas read and write configuration in the same directory as with a created access point
+	EFSAccessPointPath string `json:"efsAccessPointPath" validate:"required_with=EFSAccessPointArn,omitempty,efs_access_point_path,excluded_without_all=EFSAccessPointArn"`
+	// EFSSettings configures the EFS file system and the access point created when ConfigurationStore is efs
+	EFSSettings EFSSettings `json:"efsSettings"`
+	// ConfigurationStore is where the sync lambda stores configuration for the authorizer lambda, efs, s3, dynamodb or appconfig
+	ConfigurationStore string `json:"configurationStore" validate:"oneof=efs s3 dynamodb appconfig"`
+	// AppConfigSettings configures the rollout of configuration versions when ConfigurationStore is appconfig
+	AppConfigSettings AppConfigSettings `json:"appConfigSettings"`
+	// Version is a version of lambda function, e.g. 2.22.0
+	Version string `json:"version" validate:"required,semver"`
 	// LoggingLevel is a logging level of lambda function
-	LoggingLevel string `validate:"omitempty,oneof=debug info warn error"`
+	LoggingLevel string `json:"loggingLevel" validate:"omitempty,oneof=debug info warn error"`
 	// ReloadInterval is a reload interval of lambda function
-	ReloadInterval time.Duration `validate:"omitempty,max=1m,min=5s"`
+	// Intervals shorter than one minute must be a whole number of seconds,
+	// intervals of one minute and longer must be a whole number of minutes
+	ReloadInterval time.Duration `json:"reloadInterval" validate:"omitempty,max=24h,min=5s,reload_interval"`
 	// AnalyticsDisabled is a flag that disables analytics
-	AnalyticsDisabled bool
+	AnalyticsDisabled bool `json:"analyticsDisabled"`
 	// InjectContext is a flag that enables injecting context to the request
-	InjectContext bool
+	InjectContext bool `json:"injectContext"`
 	// EnforcementAllowUnknown is a flag that enables allowing unknown enforcement
-	EnforcementAllowUnknown bool
-	// HTTPClientRootCA is a root CA of HTTP client
-	HTTPClientRootCA string
+	EnforcementAllowUnknown bool `json:"enforcementAllowUnknown"`
+	// HTTPClientRootCA is a root CA of HTTP client, PEM encoded certificates
+	HTTPClientRootCA string `json:"httpClientRootCA" validate:"omitempty,pem_certificates"`
+	// HTTPClientRootCAFile is a path to a local file with the root CA, it's read at synth time
+	HTTPClientRootCAFile string `json:"httpClientRootCAFile" validate:"omitempty,file,pem_certificates_file,excluded_with=HTTPClientRootCA HTTPClientRootCASSMParameter HTTPClientRootCAS3URI"`
+	// HTTPClientRootCASSMParameter is a name of an existing SSM parameter with the root CA, the lambdas read it at runtime
+	HTTPClientRootCASSMParameter string `json:"httpClientRootCASSMParameter" validate:"excluded_with=HTTPClientRootCA HTTPClientRootCAS3URI"`
+	// HTTPClientRootCAS3URI is an S3 object with the root CA, e.g. s3://bucket/ca.pem, the lambdas read it at runtime
+	HTTPClientRootCAS3URI string `json:"httpClientRootCAS3URI" validate:"omitempty,s3_uri,excluded_with=HTTPClientRootCA"`
 	// HTTPClientInsecureSkipVerify is a flag that enables skipping HTTP client verification
-	HTTPClientInsecureSkipVerify bool
-	// S3BucketName is a name of S3 bucket
-	S3BucketName string
+	// It can't be used together with a root CA, which would be ignored
+	HTTPClientInsecureSkipVerify bool `json:"httpClientInsecureSkipVerify" validate:"excluded_with=HTTPClientRootCA HTTPClientRootCAFile HTTPClientRootCASSMParameter HTTPClientRootCAS3URI"`
+	// Architecture is an instruction set architecture of lambda functions, x86_64 or arm64
+	// Local zip files have to be built for the selected architecture
+	Architecture string `json:"architecture" validate:"omitempty,oneof=x86_64 arm64"`
+	// S3BucketName is a name of S3 bucket, the region of the stack is appended to it, e.g. name-eu-west-1
+	S3BucketName string `json:"s3BucketName" validate:"omitempty,s3_bucket_name"`
 	// S3AuthorizerPrefix is the file name prefix for authorizer lambda
-	S3AuthorizerPrefix string
+	S3AuthorizerPrefix string `json:"s3AuthorizerPrefix"`
 	// S3SyncPrefix is the file name prefix for sync lambda
-	S3SyncPrefix string
+	S3SyncPrefix string `json:"s3SyncPrefix"`
+	// AuthorizerEnv are extra environment variables of authorizer lambda function, they override the defaults
+	// e.g. ENFORCEMENT_CLIENT_CERTIFICATE_HEADER_NAME, variables wiring the stack such as AWS_LOCAL_CONFIGURATION can't be set
+	AuthorizerEnv map[string]string `json:"authorizerEnv" validate:"dive,keys,required,not_reserved_env,endkeys"`
+	// SyncEnv are extra environment variables of sync lambda function, they override the defaults
+	SyncEnv map[string]string `json:"syncEnv" validate:"dive,keys,required,not_reserved_env,endkeys"`
+	// AuthorizerLambdaSettings configures memory, timeout and ephemeral storage of authorizer lambda function
+	AuthorizerLambdaSettings LambdaSettings `json:"authorizerLambdaSettings"`
+	// SyncLambdaSettings configures memory, timeout and ephemeral storage of sync lambda function
+	SyncLambdaSettings LambdaSettings `json:"syncLambdaSettings"`
+	// AuthorizerProvisionedConcurrency configures provisioned concurrency of authorizer lambda function
+	AuthorizerProvisionedConcurrency ProvisionedConcurrency `json:"authorizerProvisionedConcurrency"`
+	// AuthorizerDeploymentConfig enables shifting traffic to a new authorizer lambda version with CodeDeploy
+	// e.g. Canary10Percent5Minutes, the deployment is rolled back on authorizer errors and throttles
+	AuthorizerDeploymentConfig string `json:"authorizerDeploymentConfig" validate:"omitempty,oneof=AllAtOnce Canary10Percent5Minutes Canary10Percent10Minutes Canary10Percent15Minutes Canary10Percent30Minutes Linear10PercentEvery1Minute Linear10PercentEvery2Minutes Linear10PercentEvery3Minutes Linear10PercentEvery10Minutes"`
+	// Overrides adjust props of the underlying resources before they are created
+	Overrides Overrides `json:"-"`
+}
+
+// ProvisionedConcurrency configures provisioned concurrency of a lambda function alias
+type ProvisionedConcurrency struct {
+	// MinCapacity is a number of provisioned concurrent executions, provisioned concurrency is disabled when not set
+	MinCapacity int `json:"minCapacity" validate:"required_with=MaxCapacity,omitempty,min=1"`
+	// MaxCapacity enables auto scaling of provisioned concurrency up to the given number of concurrent executions
+	MaxCapacity int `json:"maxCapacity" validate:"required_with=UtilizationTarget Schedules,omitempty,gtefield=MinCapacity"`
+	// UtilizationTarget scales provisioned concurrency to keep its utilization at the given level, between 0.1 and 0.9
+	UtilizationTarget float64 `json:"utilizationTarget" validate:"omitempty,min=0.1,max=0.9"`
+	// Schedules scale provisioned concurrency on a schedule, e.g. up for business hours and down afterwards
+	Schedules []ProvisionedConcurrencySchedule `json:"schedules" validate:"dive"`
+}
+
+// ProvisionedConcurrencySchedule sets provisioned concurrency capacity on a schedule
+type ProvisionedConcurrencySchedule struct {
+	// Expression is an Application Auto Scaling schedule expression in UTC, e.g. cron(0 8 ? * MON-FRI *)
+	Expression string `json:"expression" validate:"required"`
+	// MinCapacity is a minimum number of provisioned concurrent executions from the scheduled time
+	MinCapacity int `json:"minCapacity" validate:"min=0"`
+	// MaxCapacity is a maximum number of provisioned concurrent executions from the scheduled time
+	MaxCapacity int `json:"maxCapacity" validate:"min=1,gtefield=MinCapacity"`
+}
+
+// LambdaSettings configures resources of a lambda function
+type LambdaSettings struct {
+	// MemorySize is the amount of memory in MB available to the function, MAX_HEAP is derived from it
+	MemorySize int `json:"memorySize" validate:"omitempty,min=128,max=10240"`
+	// Timeout is the maximum execution time of the function, a whole number of seconds
+	Timeout time.Duration `json:"timeout" validate:"omitempty,min=1s,max=15m,whole_seconds"`
+	// EphemeralStorageSize is the size of /tmp in MB, Lambda's default is used when not set
+	EphemeralStorageSize int `json:"ephemeralStorageSize" validate:"omitempty,min=512,max=10240"`
+}
+
+// maxHeap is the heap limit of the function, it leaves a quarter of memory for the runtime
+func (s LambdaSettings) maxHeap() int {
+	return int(float64(s.MemorySize) * 0.75)
+}
+
+func (s LambdaSettings) ephemeralStorageSize() awscdk.Size {
+	if s.EphemeralStorageSize == 0 {
+		return nil
+	}
+	return awscdk.Size_Mebibytes(jsii.Number(s.EphemeralStorageSize))
+}
+
+func setDefaultLambdaSettings(settings *LambdaSettings, defaults LambdaSettings) {
+	if settings.MemorySize == 0 {
+		settings.MemorySize = defaults.MemorySize
+	}
+	if settings.Timeout == 0 {
+		settings.Timeout = defaults.Timeout
+	}
 }
 
-var DefaultStackProps = StackProps{
+var DefaultAuthorizerProps = AuthorizerProps{
 	LoggingLevel:       "info",
+	Architecture:       ArchitectureX86_64,
+	ConfigurationStore: ConfigurationStoreEFS,
+	AppConfigSettings: AppConfigSettings{
+		GrowthFactor: 100,
+	},
 	ReloadInterval:     time.Second * 10,
 	S3BucketName:       "cloudentity-aws-api-gateway-authorizer",
 	S3AuthorizerPrefix: "cloudentity-aws-authorizer-v2-",
 	S3SyncPrefix:       "cloudentity-aws-authorizer-v2-sync-",
+
+	ClientSecretRotationInterval: time.Hour * 24 * 90,
+	AuthorizerLambdaSettings: LambdaSettings{
+		MemorySize: 128,
+		Timeout:    time.Second * 10,
+	},
+	SyncLambdaSettings: LambdaSettings{
+		MemorySize: 128,
+		Timeout:    time.Second * 30,
+	},
 }
 
-func setDefaultStackProps(props *StackProps) {
+// DefaultStackProps are the defaults of the stack props
+//
+// Deprecated: the defaults are in DefaultAuthorizerProps, which is embedded here
+var DefaultStackProps = StackProps{AuthorizerProps: DefaultAuthorizerProps}
+
+func setDefaultProps(props *AuthorizerProps) {
 	if props.LoggingLevel == "" {
-		props.LoggingLevel = DefaultStackProps.LoggingLevel
+		props.LoggingLevel = DefaultAuthorizerProps.LoggingLevel
+	}
+	if props.Architecture == "" {
+		props.Architecture = DefaultAuthorizerProps.Architecture
+	}
+	if props.ConfigurationStore == "" {
+		props.ConfigurationStore = DefaultAuthorizerProps.ConfigurationStore
+	}
+	if props.AppConfigSettings.GrowthFactor == 0 {
+		props.AppConfigSettings.GrowthFactor = DefaultAuthorizerProps.AppConfigSettings.GrowthFactor
 	}
 	if props.ReloadInterval == 0 {
-		props.ReloadInterval = DefaultStackProps.ReloadInterval
+		props.ReloadInterval = DefaultAuthorizerProps.ReloadInterval
 	}
 	if props.S3BucketName == "" {
-		props.S3BucketName = DefaultStackProps.S3BucketName
+		props.S3BucketName = DefaultAuthorizerProps.S3BucketName
 	}
 	if props.S3AuthorizerPrefix == "" {
-		props.S3AuthorizerPrefix = DefaultStackProps.S3AuthorizerPrefix
+		props.S3AuthorizerPrefix = DefaultAuthorizerProps.S3AuthorizerPrefix
 	}
 	if props.S3SyncPrefix == "" {
-		props.S3SyncPrefix = DefaultStackProps.S3SyncPrefix
+		props.S3SyncPrefix = DefaultAuthorizerProps.S3SyncPrefix
+	}
+	if props.ClientSecretRotationInterval == 0 {
+		props.ClientSecretRotationInterval = DefaultAuthorizerProps.ClientSecretRotationInterval
 	}
+	setDefaultLambdaSettings(&props.AuthorizerLambdaSettings, DefaultAuthorizerProps.AuthorizerLambdaSettings)
+	setDefaultLambdaSettings(&props.SyncLambdaSettings, DefaultAuthorizerProps.SyncLambdaSettings)
 }
 
-func validateStackProps(props StackProps) error {
+func validateProps(props AuthorizerProps) error {
 	validate := validator.New()
+	// errors refer to fields by json names, which are the same as context param names
+	validate.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	if err := validate.RegisterValidation("reload_interval", validateReloadInterval); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("whole_minutes", validateWholeMinutes); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("whole_seconds", validateWholeSeconds); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("whole_hours", validateWholeHours); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("required_if_top", validateRequiredIfTop); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("lambda_layer_arn", validateLambdaLayerArn); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("not_reserved_env", validateNotReservedEnvKey); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("pem_certificates", validatePEMCertificates); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("pem_certificates_file", validatePEMCertificatesFile); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("s3_uri", validateS3URI); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("vpc_id", validateVpcID); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("s3_bucket_name", validateS3BucketName); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("efs_file_system_id", validateEFSFileSystemID); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("security_group_id", validateSecurityGroupID); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("efs_access_point_arn", validateEFSAccessPointArn); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("efs_access_point_path", validateEFSAccessPointPath); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("excluded_with_top", validateExcludedWithTop); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("kms_key_arn", validateKMSKeyArn); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("efs_performance_mode", validateEFSPerformanceMode); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("one_zone", validateOneZone); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("availability_zone", validateAvailabilityZone); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("posix_id", validatePosixID); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("posix_permissions", validatePosixPermissions); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("secretsmanager_secret_arn", validateSecretsManagerSecretArn); err != nil {
+		return err
+	}
 	return validate.Struct(props)
 }
+
+// validateReloadInterval checks that interval can be expressed as an EventBridge rate,
+// sub-minute intervals are handled by the step function
+func validateReloadInterval(fl validator.FieldLevel) bool {
+	interval := time.Duration(fl.Field().Int())
+	if interval < time.Minute {
+		return interval%time.Second == 0
+	}
+	return interval%time.Minute == 0
+}
+
+func validateWholeMinutes(fl validator.FieldLevel) bool {
+	return time.Duration(fl.Field().Int())%time.Minute == 0
+}
+
+// validateRequiredIfTop fails when the field is empty and the field of AuthorizerProps named in the param has the given value,
+// it works for fields of nested structs, unlike required_if
+func validateRequiredIfTop(fl validator.FieldLevel) bool {
+	name, value, _ := strings.Cut(fl.Param(), " ")
+	other := reflect.Indirect(fl.Top()).FieldByName(name)
+	return !other.IsValid() || other.String() != value || !fl.Field().IsZero()
+}
+
+var lambdaLayerArnRegexp = regexp.MustCompile(`^arn:aws[a-z-]*:lambda:[a-z0-9-]+:\d{12}:layer:[A-Za-z0-9_-]+:\d+$`)
+
+func validateLambdaLayerArn(fl validator.FieldLevel) bool {
+	return lambdaLayerArnRegexp.MatchString(fl.Field().String())
+}
+
+func validateWholeHours(fl validator.FieldLevel) bool {
+	return time.Duration(fl.Field().Int())%time.Hour == 0
+}
+
+func validateWholeSeconds(fl validator.FieldLevel) bool {
+	return time.Duration(fl.Field().Int())%time.Second == 0
+}
+
+// validatePEMCertificates checks that a field contains only PEM encoded x509 certificates, at least one
+func validatePEMCertificates(fl validator.FieldLevel) bool {
+	return isPEMCertificates([]byte(fl.Field().String()))
+}
+
+// validatePEMCertificatesFile checks that a file contains only PEM encoded x509 certificates, at least one
+func validatePEMCertificatesFile(fl validator.FieldLevel) bool {
+	data, err := os.ReadFile(fl.Field().String())
+	if err != nil {
+		return false
+	}
+	return isPEMCertificates(data)
+}
+
+func isPEMCertificates(data []byte) bool {
+	var (
+		rest  = bytes.TrimSpace(data)
+		block *pem.Block
+		count int
+	)
+
+	for len(rest) > 0 {
+		if block, rest = pem.Decode(rest); block == nil || block.Type != "CERTIFICATE" {
+			return false
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return false
+		}
+		rest = bytes.TrimSpace(rest)
+		count++
+	}
+	return count > 0
+}
+
+var vpcIDRegexp = regexp.MustCompile(`^vpc-([0-9a-f]{8}|[0-9a-f]{17})$`)
+
+func validateVpcID(fl validator.FieldLevel) bool {
+	return vpcIDRegexp.MatchString(fl.Field().String())
+}
+
+var (
+	efsFileSystemIDRegexp   = regexp.MustCompile(`^fs-([0-9a-f]{8}|[0-9a-f]{17})$`)
+	securityGroupIDRegexp   = regexp.MustCompile(`^sg-([0-9a-f]{8}|[0-9a-f]{17})$`)
+	efsAccessPointArnRegexp = regexp.MustCompile(`^arn:aws[a-z-]*:elasticfilesystem:[a-z0-9-]+:\d{12}:access-point/fsap-([0-9a-f]{8}|[0-9a-f]{17})$`)
+)
+
+func validateEFSFileSystemID(fl validator.FieldLevel) bool {
+	return efsFileSystemIDRegexp.MatchString(fl.Field().String())
+}
+
+func validateSecurityGroupID(fl validator.FieldLevel) bool {
+	return securityGroupIDRegexp.MatchString(fl.Field().String())
+}
+
+func validateEFSAccessPointArn(fl validator.FieldLevel) bool {
+	return efsAccessPointArnRegexp.MatchString(fl.Field().String())
+}
+
+// validateEFSAccessPointPath checks that the access point root directory is EfsApPath, ignoring a trailing slash
+func validateEFSAccessPointPath(fl validator.FieldLevel) bool {
+	return path.Clean(fl.Field().String()) == EfsApPath
+}
+
+var (
+	s3BucketNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]*$`)
+	ipAddressRegexp    = regexp.MustCompile(`^\d+\.\d+\.\d+\.\d+$`)
+)
+
+// s3BucketNameRegionSuffixMaxLen is a length of the longest region suffix appended to the bucket name, e.g. -ap-southeast-3
+const s3BucketNameRegionSuffixMaxLen = 15
+
+// validateS3BucketName checks the bucket name against S3 naming rules, taking the appended region into account
+func validateS3BucketName(fl validator.FieldLevel) bool {
+	name := fl.Field().String()
+
+	if len(name) < 3 || len(name) > 63-s3BucketNameRegionSuffixMaxLen {
+		return false
+	}
+	if !s3BucketNameRegexp.MatchString(name) || ipAddressRegexp.MatchString(name) {
+		return false
+	}
+	// the region is appended after a hyphen, so the name can't end with a dot
+	for _, invalid := range []string{"..", ".-", "-."} {
+		if strings.Contains(name+"-", invalid) {
+			return false
+		}
+	}
+	for _, prefix := range []string{"xn--", "sthree-", "amzn-s3-demo-"} {
+		if strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+	return true
+}
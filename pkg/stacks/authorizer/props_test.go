@@ -0,0 +1,311 @@
+package authorizer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestValidateProps(t *testing.T) {
+	rootCA := testCertificatePEM(t)
+
+	tcs := []struct {
+		name   string
+		props  func(*AuthorizerProps)
+		failed []string
+	}{
+		{
+			name:  "valid",
+			props: func(p *AuthorizerProps) {},
+		},
+		{
+			name: "valid optional values",
+			props: func(p *AuthorizerProps) {
+				p.VpcID = "vpc-0123456789abcdef0"
+				p.HTTPClientRootCA = rootCA + rootCA
+				p.S3BucketName = "my.authorizer-bucket"
+			},
+		},
+		{
+			name: "malformed values",
+			props: func(p *AuthorizerProps) {
+				p.IssuerURL = "example.authz.cloudentity.io"
+				p.Version = "latest"
+				p.VpcID = "subnet-0123456789abcdef0"
+				p.HTTPClientRootCA = "-----BEGIN CERTIFICATE-----\nnot a certificate\n-----END CERTIFICATE-----"
+				p.S3BucketName = "My_Bucket"
+			},
+			failed: []string{"httpClientRootCA", "issuerURL", "s3BucketName", "version", "vpcID"},
+		},
+		{
+			name: "insecure skip verify with root ca",
+			props: func(p *AuthorizerProps) {
+				p.HTTPClientRootCA = rootCA
+				p.HTTPClientInsecureSkipVerify = true
+			},
+			failed: []string{"httpClientInsecureSkipVerify"},
+		},
+		{
+			name: "more than one root ca source",
+			props: func(p *AuthorizerProps) {
+				p.HTTPClientRootCA = rootCA
+				p.HTTPClientRootCASSMParameter = "/pki/root-ca"
+				p.HTTPClientRootCAS3URI = "s3://pki-bucket/ca.pem"
+			},
+			failed: []string{"httpClientRootCAS3URI", "httpClientRootCASSMParameter"},
+		},
+		{
+			name: "malformed root ca sources",
+			props: func(p *AuthorizerProps) {
+				p.HTTPClientRootCAFile = "/nonexistent/ca.pem"
+				p.HTTPClientRootCAS3URI = "https://pki-bucket.s3.amazonaws.com/ca.pem"
+			},
+			failed: []string{"httpClientRootCAFile", "httpClientRootCAS3URI"},
+		},
+		{
+			name: "imported efs",
+			props: func(p *AuthorizerProps) {
+				p.VpcID = "vpc-0123456789abcdef0"
+				p.EFSFileSystemID = "fs-0123456789abcdef0"
+				p.EFSSecurityGroupID = "sg-0123456789abcdef0"
+				p.EFSAccessPointArn = "arn:aws:elasticfilesystem:eu-west-1:123456789012:access-point/fsap-0123456789abcdef0"
+				p.EFSAccessPointPath = EfsApPath + "/"
+			},
+		},
+		{
+			name: "imported efs without vpc, security group and access point path",
+			props: func(p *AuthorizerProps) {
+				p.EFSFileSystemID = "fs-0123456789abcdef0"
+				p.EFSAccessPointArn = "arn:aws:elasticfilesystem:eu-west-1:123456789012:access-point/fsap-0123456789abcdef0"
+			},
+			failed: []string{"efsAccessPointPath", "efsSecurityGroupID", "vpcID"},
+		},
+		{
+			name: "imported access point with incompatible path",
+			props: func(p *AuthorizerProps) {
+				p.VpcID = "vpc-0123456789abcdef0"
+				p.EFSFileSystemID = "fs-0123456789abcdef0"
+				p.EFSSecurityGroupID = "sg-0123456789abcdef0"
+				p.EFSAccessPointArn = "arn:aws:elasticfilesystem:eu-west-1:123456789012:access-point/fsap-0123456789abcdef0"
+				p.EFSAccessPointPath = "/authorizer"
+			},
+			failed: []string{"efsAccessPointPath"},
+		},
+		{
+			name: "imported access point without file system",
+			props: func(p *AuthorizerProps) {
+				p.EFSAccessPointArn = "arn:aws:elasticfilesystem:eu-west-1:123456789012:access-point/fsap-0123456789abcdef0"
+				p.EFSAccessPointPath = EfsApPath
+			},
+			failed: []string{"efsAccessPointArn"},
+		},
+		{
+			name: "imported efs with another configuration store",
+			props: func(p *AuthorizerProps) {
+				p.ConfigurationStore = ConfigurationStoreS3
+				p.EFSFileSystemID = "fs-0123456789abcdef0"
+				p.EFSSecurityGroupID = "sg-0123456789abcdef0"
+			},
+			failed: []string{"efsFileSystemID", "vpcID"},
+		},
+		{
+			name: "efs settings",
+			props: func(p *AuthorizerProps) {
+				p.EFSSettings = EFSSettings{
+					KMSKeyArn:             "arn:aws:kms:eu-west-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab",
+					ThroughputMode:        "provisioned",
+					ProvisionedThroughput: 10,
+					PerformanceMode:       "generalPurpose",
+					TransitionToIADays:    30,
+					OneZone:               true,
+					AvailabilityZones:     []string{"eu-west-1a"},
+					UID:                   "0",
+					GID:                   "2000",
+					Permissions:           "0750",
+				}
+			},
+		},
+		{
+			name: "invalid efs settings combinations",
+			props: func(p *AuthorizerProps) {
+				p.EFSSettings = EFSSettings{
+					ThroughputMode:    "elastic",
+					PerformanceMode:   "maxIO",
+					OneZone:           true,
+					AvailabilityZones: []string{"eu-west-1a", "eu-west-1b"},
+				}
+			},
+			failed: []string{"availabilityZones", "performanceMode"},
+		},
+		{
+			name: "provisioned throughput without provisioned mode",
+			props: func(p *AuthorizerProps) {
+				p.EFSSettings.ProvisionedThroughput = 10
+			},
+			failed: []string{"provisionedThroughput"},
+		},
+		{
+			name: "malformed efs settings",
+			props: func(p *AuthorizerProps) {
+				p.EFSSettings = EFSSettings{
+					KMSKeyArn:          "alias/efs",
+					TransitionToIADays: 180,
+					AvailabilityZones:  []string{"eu-west-1"},
+					UID:                "-1",
+					Permissions:        "rwx",
+				}
+			},
+			failed: []string{"availabilityZones[0]", "kmsKeyArn", "permissions", "transitionToIADays", "uid"},
+		},
+		{
+			name: "efs settings with imported efs",
+			props: func(p *AuthorizerProps) {
+				p.VpcID = "vpc-0123456789abcdef0"
+				p.EFSFileSystemID = "fs-0123456789abcdef0"
+				p.EFSSecurityGroupID = "sg-0123456789abcdef0"
+				p.EFSAccessPointArn = "arn:aws:elasticfilesystem:eu-west-1:123456789012:access-point/fsap-0123456789abcdef0"
+				p.EFSAccessPointPath = EfsApPath
+				p.EFSSettings.ThroughputMode = "elastic"
+				p.EFSSettings.UID = "2000"
+			},
+			failed: []string{"throughputMode", "uid"},
+		},
+		{
+			name: "utilization target out of the auto scaling range",
+			props: func(p *AuthorizerProps) {
+				p.AuthorizerProvisionedConcurrency = ProvisionedConcurrency{MinCapacity: 1, MaxCapacity: 5, UtilizationTarget: 0.95}
+			},
+			failed: []string{"utilizationTarget"},
+		},
+		{
+			name: "lambda timeout with a fraction of a second",
+			props: func(p *AuthorizerProps) {
+				p.AuthorizerLambdaSettings.Timeout = 1500 * time.Millisecond
+			},
+			failed: []string{"timeout"},
+		},
+		{
+			name: "client secret rotation without the rotation package",
+			props: func(p *AuthorizerProps) {
+				p.ClientSecret = ""
+				p.CreateClientSecret = true
+				p.RotateClientSecret = true
+			},
+			failed: []string{"rotationZip"},
+		},
+		{
+			name: "partial client secret arn",
+			props: func(p *AuthorizerProps) {
+				p.ClientSecret = ""
+				p.ClientSecretArn = "arn:aws:secretsmanager:eu-west-1:123456789012:secret:acp_client_secret"
+			},
+			failed: []string{"clientSecretArn"},
+		},
+		{
+			name: "client secret rotation interval with a fraction of an hour",
+			props: func(p *AuthorizerProps) {
+				p.ClientSecretRotationInterval = 4*time.Hour + 30*time.Minute
+			},
+			failed: []string{"clientSecretRotationInterval"},
+		},
+		{
+			name: "extra environment variables derived from props",
+			props: func(p *AuthorizerProps) {
+				p.AuthorizerEnv = map[string]string{"HTTP_CLIENT_INSECURE_SKIP_VERIFY": "true"}
+				p.SyncEnv = map[string]string{"HTTP_CLIENT_ROOT_CA": "ca"}
+			},
+			failed: []string{"authorizerEnv[HTTP_CLIENT_INSECURE_SKIP_VERIFY]", "syncEnv[HTTP_CLIENT_ROOT_CA]"},
+		},
+		{
+			name: "appconfig store without the extension layer",
+			props: func(p *AuthorizerProps) {
+				p.ConfigurationStore = ConfigurationStoreAppConfig
+			},
+			failed: []string{"extensionLayerArn"},
+		},
+		{
+			name: "malformed extension layer",
+			props: func(p *AuthorizerProps) {
+				p.ConfigurationStore = ConfigurationStoreAppConfig
+				p.AppConfigSettings.ExtensionLayerArn = "AWS-AppConfig-Extension:128"
+			},
+			failed: []string{"extensionLayerArn"},
+		},
+		{
+			name: "bucket name too long for the region suffix",
+			props: func(p *AuthorizerProps) {
+				p.S3BucketName = "cloudentity-aws-api-gateway-authorizer-artifacts-v2"
+			},
+			failed: []string{"s3BucketName"},
+		},
+		{
+			name: "bucket name ending with a dot",
+			props: func(p *AuthorizerProps) {
+				p.S3BucketName = "bucket."
+			},
+			failed: []string{"s3BucketName"},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				props  = testProps().AuthorizerProps
+				verrs  validator.ValidationErrors
+				failed []string
+			)
+			tc.props(&props)
+			setDefaultProps(&props)
+
+			err := validateProps(props)
+			if err != nil && !errors.As(err, &verrs) {
+				t.Fatal(err)
+			}
+			for _, fe := range verrs {
+				failed = append(failed, fe.Field())
+			}
+			sort.Strings(failed)
+
+			if len(failed) != len(tc.failed) {
+				t.Fatalf("expected failed fields %v, got %v", tc.failed, failed)
+			}
+			for i := range failed {
+				if failed[i] != tc.failed[i] {
+					t.Fatalf("expected failed fields %v, got %v", tc.failed, failed)
+				}
+			}
+		})
+	}
+}
+
+func testCertificatePEM(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
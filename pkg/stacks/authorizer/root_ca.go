@@ -0,0 +1,162 @@
+package authorizer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awslambda"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awss3"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsssm"
+	"github.com/aws/constructs-go/constructs/v10"
+	"github.com/aws/jsii-runtime-go"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	// lambdaEnvMaxSize is the maximum total size of keys and values of a lambda environment
+	lambdaEnvMaxSize = 4096
+	// unresolvedEnvValueSize is an estimated size of values which are resolved on deployment, e.g. ARNs
+	unresolvedEnvValueSize = 200
+	// ssmStandardParameterMaxSize is the maximum size of a standard tier SSM parameter value,
+	// larger values up to ssmAdvancedParameterMaxSize need the advanced tier
+	ssmStandardParameterMaxSize = 4096
+	ssmAdvancedParameterMaxSize = 8192
+)
+
+// rootCA delivers the HTTP client root CA to the lambdas
+// HTTPClientRootCA (also read from HTTPClientRootCAFile) is passed inline in the environment when it fits
+// or from an SSM parameter otherwise, the parameter is created on first use and shared by the lambdas
+// HTTPClientRootCASSMParameter and HTTPClientRootCAS3URI are passed as references the lambdas read at runtime
+type rootCA struct {
+	scope     constructs.Construct
+	props     AuthorizerProps
+	parameter awsssm.IStringParameter
+	bucket    awss3.IBucket
+	key       string
+}
+
+func newRootCA(scope constructs.Construct, props AuthorizerProps) *rootCA {
+	c := &rootCA{scope: scope, props: props}
+
+	if props.HTTPClientRootCASSMParameter != "" {
+		c.parameter = awsssm.StringParameter_FromStringParameterName(scope, jsii.String("HTTPClientRootCA"), jsii.String(props.HTTPClientRootCASSMParameter))
+	}
+	if props.HTTPClientRootCAS3URI != "" {
+		bucket, key, _ := parseS3URI(props.HTTPClientRootCAS3URI)
+		c.bucket = awss3.Bucket_FromBucketName(scope, jsii.String("HTTPClientRootCABucket"), jsii.String(bucket))
+		c.key = key
+	}
+
+	return c
+}
+
+// setEnv passes the root CA reference, or the root CA inline when the environment stays within the lambda limit,
+// otherwise it passes the name of the SSM parameter holding it
+func (c *rootCA) setEnv(env map[string]*string) {
+	switch {
+	case c.props.HTTPClientRootCASSMParameter != "":
+		env["HTTP_CLIENT_ROOT_CA_SSM_PARAMETER"] = jsii.String(c.props.HTTPClientRootCASSMParameter)
+		return
+	case c.props.HTTPClientRootCAS3URI != "":
+		env["HTTP_CLIENT_ROOT_CA_S3_URI"] = jsii.String(c.props.HTTPClientRootCAS3URI)
+		return
+	}
+
+	env["HTTP_CLIENT_ROOT_CA"] = jsii.String(c.props.HTTPClientRootCA)
+	if c.props.HTTPClientRootCA == "" || envSize(env) <= lambdaEnvMaxSize {
+		return
+	}
+
+	delete(env, "HTTP_CLIENT_ROOT_CA")
+	env["HTTP_CLIENT_ROOT_CA_SSM_PARAMETER"] = c.getParameter().ParameterName()
+}
+
+// grantRead allows the lambda to read the SSM parameter or the S3 object if its environment refers to it
+func (c *rootCA) grantRead(lambda awslambda.Function, env map[string]*string) {
+	if _, ok := env["HTTP_CLIENT_ROOT_CA_SSM_PARAMETER"]; ok {
+		c.parameter.GrantRead(lambda)
+	}
+	if _, ok := env["HTTP_CLIENT_ROOT_CA_S3_URI"]; ok {
+		c.bucket.GrantRead(lambda, jsii.String(c.key))
+	}
+}
+
+func (c *rootCA) getParameter() awsssm.IStringParameter {
+	if c.parameter != nil {
+		return c.parameter
+	}
+
+	tier := awsssm.ParameterTier_STANDARD
+	if len(c.props.HTTPClientRootCA) > ssmStandardParameterMaxSize {
+		tier = awsssm.ParameterTier_ADVANCED
+	}
+
+	c.parameter = awsssm.NewStringParameter(c.scope, jsii.String("HTTPClientRootCA"), override(c.props.Overrides.RootCAParameter, &awsssm.StringParameterProps{
+		Description: jsii.String("Root CA of the HTTP client of the authorizer lambdas"),
+		StringValue: jsii.String(c.props.HTTPClientRootCA),
+		Tier:        tier,
+	}))
+
+	if len(c.props.HTTPClientRootCA) > ssmAdvancedParameterMaxSize {
+		awscdk.Annotations_Of(c.parameter).AddError(jsii.String(fmt.Sprintf(
+			"httpClientRootCA has %d bytes, it exceeds the %d bytes limit of an SSM parameter",
+			len(c.props.HTTPClientRootCA), ssmAdvancedParameterMaxSize,
+		)))
+	}
+
+	return c.parameter
+}
+
+// readRootCAFile sets HTTPClientRootCA from HTTPClientRootCAFile, so it's delivered as an inline root CA
+func readRootCAFile(props *AuthorizerProps) error {
+	if props.HTTPClientRootCAFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(props.HTTPClientRootCAFile)
+	if err != nil {
+		return fmt.Errorf("could not read root CA file %w", err)
+	}
+	props.HTTPClientRootCA = string(data)
+	return nil
+}
+
+// parseS3URI splits s3://bucket/key into the bucket name and the object key
+func parseS3URI(uri string) (bucket string, key string, ok bool) {
+	if !strings.HasPrefix(uri, "s3://") {
+		return "", "", false
+	}
+	bucket, key, ok = strings.Cut(strings.TrimPrefix(uri, "s3://"), "/")
+	return bucket, key, ok && bucket != "" && key != ""
+}
+
+func validateS3URI(fl validator.FieldLevel) bool {
+	_, _, ok := parseS3URI(fl.Field().String())
+	return ok
+}
+
+// envSize estimates the total size of the lambda environment, values resolved on deployment are estimated
+func envSize(env map[string]*string) int {
+	size := 0
+	for k, v := range env {
+		size += len(k)
+		if *awscdk.Token_IsUnresolved(v) {
+			size += unresolvedEnvValueSize
+			continue
+		}
+		size += len(*v)
+	}
+	return size
+}
+
+// checkEnvSize fails the synth when the lambda environment exceeds the lambda limit
+func checkEnvSize(lambda awslambda.Function, env map[string]*string) {
+	if size := envSize(env); size > lambdaEnvMaxSize {
+		awscdk.Annotations_Of(lambda).AddError(jsii.String(fmt.Sprintf(
+			"environment of %s has an estimated size of %d bytes, it exceeds the %d bytes lambda limit, reduce authorizerEnv or syncEnv",
+			*lambda.Node().Id(), size, lambdaEnvMaxSize,
+		)))
+	}
+}
@@ -0,0 +1,193 @@
+package authorizer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2/assertions"
+	"github.com/aws/jsii-runtime-go"
+)
+
+func TestRootCAEnvironment(t *testing.T) {
+	cert := testCertificatePEM(t)
+
+	tcs := []struct {
+		name       string
+		rootCA     string
+		env        map[string]string
+		parameters int
+		tier       string
+		failed     bool
+	}{
+		{
+			name:   "inline",
+			rootCA: cert,
+		},
+		{
+			name:       "standard parameter",
+			rootCA:     strings.Repeat(cert, 3500/len(cert)+1),
+			parameters: 1,
+			tier:       "Standard",
+		},
+		{
+			name:       "advanced parameter",
+			rootCA:     strings.Repeat(cert, 6000/len(cert)+1),
+			parameters: 1,
+			tier:       "Advanced",
+		},
+		{
+			name:   "environment too large",
+			env:    map[string]string{"EXTRA": strings.Repeat("x", lambdaEnvMaxSize)},
+			failed: true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			props := testProps()
+			props.HTTPClientRootCA = tc.rootCA
+			props.AuthorizerEnv = tc.env
+
+			stack := synthStack(t, props)
+			template := assertions.Template_FromStack(stack.Stack, nil)
+			template.ResourceCountIs(jsii.String("AWS::SSM::Parameter"), jsii.Number(tc.parameters))
+
+			if tc.parameters > 0 {
+				template.HasResourceProperties(jsii.String("AWS::SSM::Parameter"), map[string]interface{}{
+					"Tier": tc.tier,
+				})
+				template.HasResourceProperties(jsii.String("AWS::Lambda::Function"), map[string]interface{}{
+					"Environment": map[string]interface{}{
+						"Variables": assertions.Match_ObjectLike(&map[string]interface{}{
+							"HTTP_CLIENT_ROOT_CA":               assertions.Match_Absent(),
+							"HTTP_CLIENT_ROOT_CA_SSM_PARAMETER": assertions.Match_AnyValue(),
+						}),
+					},
+				})
+			} else if tc.rootCA != "" {
+				template.HasResourceProperties(jsii.String("AWS::Lambda::Function"), map[string]interface{}{
+					"Environment": map[string]interface{}{
+						"Variables": assertions.Match_ObjectLike(&map[string]interface{}{
+							"HTTP_CLIENT_ROOT_CA": tc.rootCA,
+						}),
+					},
+				})
+			}
+
+			annotations := assertions.Annotations_FromStack(stack.Stack)
+			errors := annotations.FindError(jsii.String("*"), assertions.Match_StringLikeRegexp(jsii.String("exceeds the 4096 bytes lambda limit")))
+			if failed := len(*errors) > 0; failed != tc.failed {
+				t.Fatalf("expected failed %v, got %v", tc.failed, failed)
+			}
+		})
+	}
+}
+
+func TestRootCASources(t *testing.T) {
+	var (
+		cert = testCertificatePEM(t)
+		file = filepath.Join(t.TempDir(), "ca.pem")
+	)
+
+	if err := os.WriteFile(file, []byte(cert), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tcs := []struct {
+		name   string
+		props  func(*AuthorizerProps)
+		env    map[string]interface{}
+		action string
+	}{
+		{
+			name:  "file",
+			props: func(p *AuthorizerProps) { p.HTTPClientRootCAFile = file },
+			env:   map[string]interface{}{"HTTP_CLIENT_ROOT_CA": cert},
+		},
+		{
+			name:   "ssm parameter",
+			props:  func(p *AuthorizerProps) { p.HTTPClientRootCASSMParameter = "/pki/root-ca" },
+			env:    map[string]interface{}{"HTTP_CLIENT_ROOT_CA_SSM_PARAMETER": "/pki/root-ca"},
+			action: "ssm:GetParameter",
+		},
+		{
+			name:   "s3 object",
+			props:  func(p *AuthorizerProps) { p.HTTPClientRootCAS3URI = "s3://pki-bucket/root/ca.pem" },
+			env:    map[string]interface{}{"HTTP_CLIENT_ROOT_CA_S3_URI": "s3://pki-bucket/root/ca.pem"},
+			action: "s3:GetObject*",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			props := testProps()
+			tc.props(&props.AuthorizerProps)
+
+			template := synthTemplate(t, props)
+			template.HasResourceProperties(jsii.String("AWS::Lambda::Function"), map[string]interface{}{
+				"Environment": map[string]interface{}{
+					"Variables": assertions.Match_ObjectLike(&tc.env),
+				},
+			})
+
+			if tc.action != "" {
+				template.HasResourceProperties(jsii.String("AWS::IAM::Policy"), map[string]interface{}{
+					"PolicyDocument": map[string]interface{}{
+						"Statement": assertions.Match_ArrayWith(&[]interface{}{
+							assertions.Match_ObjectLike(&map[string]interface{}{
+								"Action": assertions.Match_ArrayWith(&[]interface{}{tc.action}),
+							}),
+						}),
+					},
+				})
+			}
+		})
+	}
+}
+
+func TestRootCAEnvironmentWithStoreVariables(t *testing.T) {
+	props := testProps()
+	props.HTTPClientRootCA = testCertificatePEM(t)
+	props.ConfigurationStore = ConfigurationStoreAppConfig
+	props.AppConfigSettings.ExtensionLayerArn = testAppConfigLayerArn
+
+	// pad the authorizer environment so that it fits the limit only when the store variables are left out
+	size := 0
+	for k, v := range authorizerLambdaEnv(t, synthTemplate(t, props)) {
+		size += len(k)
+		if s, ok := v.(string); ok {
+			size += len(s)
+		} else {
+			size += unresolvedEnvValueSize
+		}
+	}
+	props.AuthorizerEnv = map[string]string{"PADDING": strings.Repeat("x", lambdaEnvMaxSize-size-len("PADDING")+10)}
+
+	env := authorizerLambdaEnv(t, synthTemplate(t, props))
+	if _, ok := env["AWS_APPCONFIG_EXTENSION_POLL_INTERVAL_SECONDS"]; !ok {
+		t.Fatalf("expected the store variables in the authorizer environment, got %v", env)
+	}
+	if _, ok := env["HTTP_CLIENT_ROOT_CA"]; ok {
+		t.Error("expected the root CA moved to an SSM parameter")
+	}
+	if _, ok := env["HTTP_CLIENT_ROOT_CA_SSM_PARAMETER"]; !ok {
+		t.Error("expected the root CA SSM parameter in the authorizer environment")
+	}
+}
+
+// authorizerLambdaEnv returns the environment variables of the authorizer lambda in the template
+func authorizerLambdaEnv(t *testing.T, template assertions.Template) map[string]interface{} {
+	t.Helper()
+
+	for id, resource := range *template.FindResources(jsii.String("AWS::Lambda::Function"), nil) {
+		if !strings.HasPrefix(id, "AuthorizerLambda") {
+			continue
+		}
+		properties := (*resource)["Properties"].(map[string]interface{})
+		return properties["Environment"].(map[string]interface{})["Variables"].(map[string]interface{})
+	}
+	t.Fatal("authorizer lambda not found")
+	return nil
+}
@@ -0,0 +1,57 @@
+package authorizer
+
+import (
+	"strconv"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsec2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awslambda"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awssecretsmanager"
+	"github.com/aws/constructs-go/constructs/v10"
+	"github.com/aws/jsii-runtime-go"
+)
+
+// rotateClientSecret deploys the rotation lambda which calls ACP to rotate the client secret
+// and stores the new value in the secret, the authorizer and sync lambdas read the secret at runtime
+// so they pick up the new value without a redeploy
+func rotateClientSecret(scope constructs.Construct, vpc awsec2.IVpc, secret awssecretsmanager.ISecret, ca *rootCA, props AuthorizerProps) awslambda.Function {
+	var (
+		code    = getLocalCode(props.RotationZip)
+		lambda  awslambda.Function
+		memSize = 128
+		maxHeap = int(float64(memSize) * 0.75)
+	)
+
+	env := map[string]*string{
+		"ACP_CLIENT_ID":                    jsii.String(props.ClientID),
+		"ACP_ISSUER_URL":                   jsii.String(props.IssuerURL),
+		"LOGGING_LEVEL":                    jsii.String(props.LoggingLevel),
+		"HTTP_CLIENT_INSECURE_SKIP_VERIFY": jsii.String(strconv.FormatBool(props.HTTPClientInsecureSkipVerify)),
+		"MAX_HEAP":                         jsii.String(strconv.Itoa(maxHeap)),
+	}
+	ca.setEnv(env)
+
+	lambda = awslambda.NewFunction(scope, jsii.String("RotationLambda"), override(props.Overrides.RotationLambda, &awslambda.FunctionProps{
+		Code:         code,
+		Handler:      jsii.String("bootstrap"),
+		Runtime:      awslambda.Runtime_PROVIDED_AL2023(),
+		Architecture: getLambdaArchitecture(props),
+		MemorySize:   jsii.Number(memSize),
+		Timeout:      awscdk.Duration_Seconds(jsii.Number(30)),
+		Environment:  &env,
+		Vpc:          vpc,
+	}))
+
+	ca.grantRead(lambda, env)
+	checkEnvSize(lambda, env)
+
+	// the secret value is rotated on schedule only, a placeholder value of a secret created by the stack
+	// is not a valid client secret, so it can't be rotated on deployment
+	secret.AddRotationSchedule(jsii.String("ClientSecretRotation"), override(props.Overrides.ClientSecretRotation, &awssecretsmanager.RotationScheduleOptions{
+		RotationLambda:            lambda,
+		AutomaticallyAfter:        awscdk.Duration_Hours(jsii.Number(props.ClientSecretRotationInterval.Hours())),
+		RotateImmediatelyOnUpdate: jsii.Bool(false),
+	}))
+
+	return lambda
+}
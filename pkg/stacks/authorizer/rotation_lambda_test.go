@@ -0,0 +1,90 @@
+package authorizer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2/assertions"
+	"github.com/aws/jsii-runtime-go"
+)
+
+func TestClientSecretRotation(t *testing.T) {
+	rotationZip := filepath.Join(t.TempDir(), "aws-authorizer-rotation.zip")
+	if err := os.WriteFile(rotationZip, []byte("rotation lambda"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tcs := []struct {
+		name     string
+		props    func(*AuthorizerProps)
+		schedule string
+	}{
+		{
+			name: "created secret with the default interval",
+			props: func(p *AuthorizerProps) {
+				p.CreateClientSecret = true
+			},
+			schedule: "rate(90 days)",
+		},
+		{
+			name: "existing secret",
+			props: func(p *AuthorizerProps) {
+				p.ClientSecretArn = "arn:aws:secretsmanager:eu-west-1:123456789012:secret:acp-client-secret-AbCdEf"
+				p.ClientSecretRotationInterval = 12 * time.Hour
+			},
+			schedule: "rate(12 hours)",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			props := testProps()
+			props.ClientSecret = ""
+			props.RotateClientSecret = true
+			props.RotationZip = rotationZip
+			tc.props(&props.AuthorizerProps)
+
+			template := synthTemplate(t, props)
+
+			// authorizer, sync and rotation lambdas
+			template.ResourceCountIs(jsii.String("AWS::Lambda::Function"), jsii.Number(3))
+			template.HasResourceProperties(jsii.String("AWS::Lambda::Function"), map[string]interface{}{
+				"Handler": "bootstrap",
+				"Runtime": "provided.al2023",
+				"Environment": map[string]interface{}{
+					"Variables": assertions.Match_ObjectLike(&map[string]interface{}{
+						"ACP_CLIENT_ID":  "client-id",
+						"ACP_ISSUER_URL": "https://example.authz.cloudentity.io/example/system",
+					}),
+				},
+			})
+
+			template.ResourceCountIs(jsii.String("AWS::SecretsManager::RotationSchedule"), jsii.Number(1))
+			template.HasResourceProperties(jsii.String("AWS::SecretsManager::RotationSchedule"), map[string]interface{}{
+				"RotateImmediatelyOnUpdate": false,
+				"RotationLambdaARN": map[string]interface{}{
+					"Fn::GetAtt": []interface{}{assertions.Match_StringLikeRegexp(jsii.String("RotationLambda")), "Arn"},
+				},
+				"RotationRules": map[string]interface{}{
+					"ScheduleExpression": tc.schedule,
+				},
+			})
+			template.HasResourceProperties(jsii.String("AWS::Lambda::Permission"), map[string]interface{}{
+				"Action":    "lambda:InvokeFunction",
+				"Principal": "secretsmanager.amazonaws.com",
+			})
+		})
+	}
+}
+
+func TestClientSecretWithoutRotation(t *testing.T) {
+	props := testProps()
+	props.ClientSecret = ""
+	props.CreateClientSecret = true
+
+	template := synthTemplate(t, props)
+	template.ResourceCountIs(jsii.String("AWS::Lambda::Function"), jsii.Number(2))
+	template.ResourceCountIs(jsii.String("AWS::SecretsManager::RotationSchedule"), jsii.Number(0))
+}
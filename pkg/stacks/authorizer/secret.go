@@ -0,0 +1,49 @@
+package authorizer
+
+import (
+	"regexp"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2/awslambda"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awssecretsmanager"
+	"github.com/aws/constructs-go/constructs/v10"
+	"github.com/aws/jsii-runtime-go"
+	"github.com/go-playground/validator/v10"
+)
+
+// getClientSecret returns the Secrets Manager secret holding the client secret
+// or nil when the client secret is passed inline
+func getClientSecret(scope constructs.Construct, props AuthorizerProps) awssecretsmanager.ISecret {
+	if props.ClientSecretArn != "" {
+		return awssecretsmanager.Secret_FromSecretCompleteArn(scope, jsii.String("ClientSecret"), jsii.String(props.ClientSecretArn))
+	}
+
+	if props.CreateClientSecret {
+		return awssecretsmanager.NewSecret(scope, jsii.String("ClientSecret"), override(props.Overrides.ClientSecret, &awssecretsmanager.SecretProps{
+			Description: jsii.String("Cloudentity ACP client secret used by the authorizer and sync lambdas"),
+		}))
+	}
+
+	return nil
+}
+
+// setClientSecretEnv passes either the secret ARN or the inline client secret to the lambda
+func setClientSecretEnv(env map[string]*string, secret awssecretsmanager.ISecret, props AuthorizerProps) {
+	if secret != nil {
+		env["ACP_CLIENT_SECRET_ARN"] = secret.SecretArn()
+		return
+	}
+	env["ACP_CLIENT_SECRET"] = jsii.String(props.ClientSecret)
+}
+
+func grantClientSecretRead(secret awssecretsmanager.ISecret, lambda awslambda.Function) {
+	if secret != nil {
+		secret.GrantRead(lambda, nil)
+	}
+}
+
+// secretArnRegexp matches a complete secret ARN, with the random suffix Secrets Manager appends to the name
+var secretArnRegexp = regexp.MustCompile(`^arn:aws[a-z-]*:secretsmanager:[a-z0-9-]+:\d{12}:secret:[A-Za-z0-9/_+=.@-]+-[A-Za-z0-9]{6}$`)
+
+func validateSecretsManagerSecretArn(fl validator.FieldLevel) bool {
+	return secretArnRegexp.MatchString(fl.Field().String())
+}
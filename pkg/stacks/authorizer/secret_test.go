@@ -0,0 +1,84 @@
+package authorizer
+
+import (
+	"testing"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2/assertions"
+	"github.com/aws/jsii-runtime-go"
+)
+
+func TestClientSecret(t *testing.T) {
+	const secretArn = "arn:aws:secretsmanager:eu-west-1:123456789012:secret:acp-client-secret-AbCdEf"
+
+	tcs := []struct {
+		name    string
+		props   func(*AuthorizerProps)
+		secrets int
+		env     map[string]interface{}
+		grant   interface{}
+	}{
+		{
+			name:  "inline",
+			props: func(p *AuthorizerProps) {},
+			env: map[string]interface{}{
+				"ACP_CLIENT_SECRET":     "client-secret",
+				"ACP_CLIENT_SECRET_ARN": assertions.Match_Absent(),
+			},
+		},
+		{
+			name: "existing secret",
+			props: func(p *AuthorizerProps) {
+				p.ClientSecret = ""
+				p.ClientSecretArn = secretArn
+			},
+			env: map[string]interface{}{
+				"ACP_CLIENT_SECRET":     assertions.Match_Absent(),
+				"ACP_CLIENT_SECRET_ARN": secretArn,
+			},
+			grant: secretArn,
+		},
+		{
+			name: "created secret",
+			props: func(p *AuthorizerProps) {
+				p.ClientSecret = ""
+				p.CreateClientSecret = true
+			},
+			secrets: 1,
+			env: map[string]interface{}{
+				"ACP_CLIENT_SECRET":     assertions.Match_Absent(),
+				"ACP_CLIENT_SECRET_ARN": map[string]interface{}{"Ref": assertions.Match_StringLikeRegexp(jsii.String("ClientSecret"))},
+			},
+			grant: map[string]interface{}{"Ref": assertions.Match_StringLikeRegexp(jsii.String("ClientSecret"))},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			props := testProps()
+			tc.props(&props.AuthorizerProps)
+
+			template := synthTemplate(t, props)
+			template.ResourceCountIs(jsii.String("AWS::SecretsManager::Secret"), jsii.Number(tc.secrets))
+
+			// both the authorizer and the sync lambda get the client secret
+			template.ResourcePropertiesCountIs(jsii.String("AWS::Lambda::Function"), map[string]interface{}{
+				"Environment": map[string]interface{}{
+					"Variables": assertions.Match_ObjectLike(&tc.env),
+				},
+			}, jsii.Number(2))
+
+			if tc.grant != nil {
+				template.ResourcePropertiesCountIs(jsii.String("AWS::IAM::Policy"), map[string]interface{}{
+					"PolicyDocument": map[string]interface{}{
+						"Statement": assertions.Match_ArrayWith(&[]interface{}{
+							assertions.Match_ObjectLike(&map[string]interface{}{
+								"Action":   []interface{}{"secretsmanager:GetSecretValue", "secretsmanager:DescribeSecret"},
+								"Resource": tc.grant,
+							}),
+						}),
+					},
+				}, jsii.Number(2))
+			}
+		})
+	}
+}
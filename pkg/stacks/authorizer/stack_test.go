@@ -0,0 +1,45 @@
+package authorizer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewStackLogicalIDs(t *testing.T) {
+	rotationZip := filepath.Join(t.TempDir(), "aws-authorizer-rotation.zip")
+	if err := os.WriteFile(rotationZip, []byte("rotation lambda"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	props := testProps()
+	props.ClientSecret = ""
+	props.CreateClientSecret = true
+	props.RotateClientSecret = true
+	props.RotationZip = rotationZip
+	props.AuthorizerDeploymentConfig = "Canary10Percent5Minutes"
+
+	// the resources are created in the stack scope, a changed logical id would replace them in existing deployments
+	resources := (*synthTemplate(t, props).ToJSON())["Resources"].(map[string]interface{})
+	for id, typ := range map[string]string{
+		"AuthorizerLambda972EEEAB":                  "AWS::Lambda::Function",
+		"SyncLambda80EEFC7D":                        "AWS::Lambda::Function",
+		"RotationLambda14AA3F4C":                    "AWS::Lambda::Function",
+		"ClientSecret6773D8CE":                      "AWS::SecretsManager::Secret",
+		"AuthorizerDeploymentGroupCD826FF6":         "AWS::CodeDeploy::DeploymentGroup",
+		"AuthorizerConfigurationFileSystemDFBA3536": "AWS::EFS::FileSystem",
+		"SQSQueue7674CD17":                          "AWS::SQS::Queue",
+		"DeadLetterQueue9F481546":                   "AWS::SQS::Queue",
+		"SyncLooper64331715":                        "AWS::StepFunctions::StateMachine",
+		"RunStepFunction907FCDBD":                   "AWS::Events::Rule",
+	} {
+		resource, ok := resources[id].(map[string]interface{})
+		if !ok {
+			t.Errorf("expected resource %s", id)
+			continue
+		}
+		if resource["Type"] != typ {
+			t.Errorf("expected %s to be %s, got %v", id, typ, resource["Type"])
+		}
+	}
+}
@@ -4,57 +4,63 @@ import (
 	"strconv"
 
 	"github.com/aws/aws-cdk-go/awscdk/v2"
-	"github.com/aws/aws-cdk-go/awscdk/v2/awsec2"
-	"github.com/aws/aws-cdk-go/awscdk/v2/awsefs"
 	"github.com/aws/aws-cdk-go/awscdk/v2/awsiam"
 	"github.com/aws/aws-cdk-go/awscdk/v2/awslambda"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awssecretsmanager"
+	"github.com/aws/constructs-go/constructs/v10"
 	"github.com/aws/jsii-runtime-go"
 )
 
-func createSyncLambda(stack awscdk.Stack, authorizer awslambda.Function, vpc awsec2.IVpc, efsAP awsefs.AccessPoint, props StackProps) awslambda.Function {
+func createSyncLambda(scope constructs.Construct, authorizer awslambda.IFunction, store configurationStore, clientSecret awssecretsmanager.ISecret, ca *rootCA, props AuthorizerProps) awslambda.Function {
 	var (
-		code    awslambda.Code
-		lambda  awslambda.Function
-		memSize = 128
-		maxHeap = int(float64(memSize) * 0.75)
+		code   awslambda.Code
+		lambda awslambda.Function
 	)
 	if props.SyncZip != "" {
 		code = getLocalCode(props.SyncZip)
 	} else {
-		code = getCodeFromS3(stack, props, props.S3SyncPrefix+props.Version+".zip")
+		code = getCodeFromS3(scope, props, getS3FileName(props.S3SyncPrefix, props))
 	}
 	syncLambdaEnvVars := map[string]*string{
 		"ACP_CLIENT_ID":                    jsii.String(props.ClientID),
-		"ACP_CLIENT_SECRET":                jsii.String(props.ClientSecret),
 		"ACP_ISSUER_URL":                   jsii.String(props.IssuerURL),
 		"LOGGING_LEVEL":                    jsii.String(props.LoggingLevel),
 		"ANALYTICS_ENABLED":                jsii.String(strconv.FormatBool(!props.AnalyticsDisabled)),
-		"HTTP_CLIENT_ROOT_CA":              jsii.String(props.HTTPClientRootCA),
 		"HTTP_CLIENT_INSECURE_SKIP_VERIFY": jsii.String(strconv.FormatBool(props.HTTPClientInsecureSkipVerify)),
-		"AWS_LOCAL_CONFIGURATION":          jsii.String(EfsMountPath),
 		"AWS_AUTHORIZER_ARN":               authorizer.FunctionArn(),
 		"AWS_CREATE_AUTHORIZER":            jsii.String(strconv.FormatBool(!props.ManuallyCreateAuthorizer)),
-		"MAX_HEAP":                         jsii.String(strconv.Itoa(maxHeap)),
+		"MAX_HEAP":                         jsii.String(strconv.Itoa(props.SyncLambdaSettings.maxHeap())),
 	}
+	store.setEnv(syncLambdaEnvVars)
+	mergeEnv(syncLambdaEnvVars, props.SyncEnv)
+	setClientSecretEnv(syncLambdaEnvVars, clientSecret, props)
+	ca.setEnv(syncLambdaEnvVars)
 
-	lambda = awslambda.NewFunction(stack, jsii.String("SyncLambda"), &awslambda.FunctionProps{
+	functionProps := &awslambda.FunctionProps{
 		Code:                         code,
 		Handler:                      jsii.String("bootstrap"),
 		Runtime:                      awslambda.Runtime_PROVIDED_AL2023(),
-		MemorySize:                   jsii.Number(128),
-		Timeout:                      awscdk.Duration_Seconds(jsii.Number(30)),
+		Architecture:                 getLambdaArchitecture(props),
+		MemorySize:                   jsii.Number(props.SyncLambdaSettings.MemorySize),
+		Timeout:                      awscdk.Duration_Seconds(jsii.Number(props.SyncLambdaSettings.Timeout.Seconds())),
+		EphemeralStorageSize:         props.SyncLambdaSettings.ephemeralStorageSize(),
 		Environment:                  &syncLambdaEnvVars,
-		Vpc:                          vpc,
-		Filesystem:                   awslambda.FileSystem_FromEfsAccessPoint(efsAP, jsii.String(EfsMountPath)),
 		ReservedConcurrentExecutions: jsii.Number(1),
-	})
+	}
+	store.attach(functionProps)
+
+	lambda = awslambda.NewFunction(scope, jsii.String("SyncLambda"), override(props.Overrides.SyncLambda, functionProps))
 
-	attachSyncLambdaPolicy(stack, lambda, props)
+	attachSyncLambdaPolicy(scope, lambda, props)
+	store.addWriter(lambda)
+	grantClientSecretRead(clientSecret, lambda)
+	ca.grantRead(lambda, syncLambdaEnvVars)
+	checkEnvSize(lambda, syncLambdaEnvVars)
 
 	return lambda
 }
 
-func attachSyncLambdaPolicy(stack awscdk.Stack, lambda awslambda.Function, props StackProps) {
+func attachSyncLambdaPolicy(scope constructs.Construct, lambda awslambda.Function, props AuthorizerProps) {
 	statements := []awsiam.PolicyStatement{
 		awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
 			Actions: &[]*string{
@@ -99,7 +105,7 @@ func attachSyncLambdaPolicy(stack awscdk.Stack, lambda awslambda.Function, props
 			}))
 	}
 
-	lambda.Role().AttachInlinePolicy(awsiam.NewPolicy(stack, jsii.String("SyncLambdaPolicy"), &awsiam.PolicyProps{
+	lambda.Role().AttachInlinePolicy(awsiam.NewPolicy(scope, jsii.String("SyncLambdaPolicy"), override(props.Overrides.SyncLambdaPolicy, &awsiam.PolicyProps{
 		Statements: &statements,
-	}))
+	})))
 }
@@ -12,81 +12,141 @@ import (
 	"github.com/aws/aws-cdk-go/awscdk/v2/awssqs"
 	"github.com/aws/aws-cdk-go/awscdk/v2/awsstepfunctions"
 	"github.com/aws/aws-cdk-go/awscdk/v2/awsstepfunctionstasks"
+	"github.com/aws/constructs-go/constructs/v10"
 	"github.com/aws/jsii-runtime-go"
 )
 
-func triggerLambdaInIntervals(stack awscdk.Stack, lambda awslambda.Function, props StackProps) {
-	var (
-		sqsQueue     awssqs.Queue
-		stateMachine awsstepfunctions.StateMachine
-	)
+// syncTrigger holds the resources triggering the sync lambda,
+// the queues and the state machine are nil when the sync lambda is triggered directly by the rule
+type syncTrigger struct {
+	rule            awsevents.Rule
+	queue           awssqs.Queue
+	deadLetterQueue awssqs.Queue
+	stateMachine    awsstepfunctions.StateMachine
+}
+
+func triggerLambdaInIntervals(scope constructs.Construct, lambda awslambda.Function, props AuthorizerProps) syncTrigger {
+	var trigger syncTrigger
 
 	if props.ReloadInterval >= 1*time.Minute {
-		createDirectEventBridgeRule(stack, lambda)
-		return
+		trigger.rule = createDirectEventBridgeRule(scope, lambda, props)
+		return trigger
 	}
 
-	sqsQueue = createSQSQueue(stack)
-	stateMachine = createStateMachine(stack, sqsQueue, props)
-	createEventBridgeRule(stack, stateMachine)
+	trigger.queue, trigger.deadLetterQueue = createSQSQueue(scope, props)
+	trigger.stateMachine = createStateMachine(scope, trigger.queue, props)
+	trigger.rule = createEventBridgeRule(scope, trigger.stateMachine, props)
 
-	lambda.AddEventSource(awslambdaeventsources.NewSqsEventSource(sqsQueue, &awslambdaeventsources.SqsEventSourceProps{
+	lambda.AddEventSource(awslambdaeventsources.NewSqsEventSource(trigger.queue, &awslambdaeventsources.SqsEventSourceProps{
 		BatchSize: jsii.Number(1),
 	}))
+
+	return trigger
 }
 
-func createDirectEventBridgeRule(stack awscdk.Stack, lambda awslambda.Function) {
-	rule := awsevents.NewRule(stack, jsii.String("Run Sync Lambda"), &awsevents.RuleProps{
-		Schedule: awsevents.Schedule_Rate(awscdk.Duration_Minutes(jsii.Number(EventBridgeTriggerIntervalMinutes))),
-	})
+func createDirectEventBridgeRule(scope constructs.Construct, lambda awslambda.Function, props AuthorizerProps) awsevents.Rule {
+	rule := awsevents.NewRule(scope, jsii.String("Run Sync Lambda"), override(props.Overrides.SyncRule, &awsevents.RuleProps{
+		Schedule: awsevents.Schedule_Rate(awscdk.Duration_Minutes(jsii.Number(props.ReloadInterval.Minutes()))),
+	}))
 	rule.AddTarget(awseventstargets.NewLambdaFunction(lambda, &awseventstargets.LambdaFunctionProps{}))
+	return rule
 }
 
-func createSQSQueue(stack awscdk.Stack) awssqs.Queue {
-	deadLetterQueue := awssqs.NewQueue(stack, jsii.String("DeadLetterQueue"), &awssqs.QueueProps{
+func createSQSQueue(scope constructs.Construct, props AuthorizerProps) (awssqs.Queue, awssqs.Queue) {
+	deadLetterQueue := awssqs.NewQueue(scope, jsii.String("DeadLetterQueue"), override(props.Overrides.SyncDeadLetterQueue, &awssqs.QueueProps{
 		RetentionPeriod: awscdk.Duration_Minutes(jsii.Number(1)),
 		RemovalPolicy:   awscdk.RemovalPolicy_DESTROY,
-	})
+	}))
 
-	return awssqs.NewQueue(stack, jsii.String("SQSQueue"), &awssqs.QueueProps{
-		VisibilityTimeout: awscdk.Duration_Seconds(jsii.Number(30)),
+	// visibility timeout has to be at least as long as the timeout of the sync lambda consuming the queue
+	queue := awssqs.NewQueue(scope, jsii.String("SQSQueue"), override(props.Overrides.SyncQueue, &awssqs.QueueProps{
+		VisibilityTimeout: awscdk.Duration_Seconds(jsii.Number(props.SyncLambdaSettings.Timeout.Seconds())),
 		DeadLetterQueue: &awssqs.DeadLetterQueue{
 			Queue:           deadLetterQueue,
 			MaxReceiveCount: jsii.Number(1),
 		},
 		RemovalPolicy: awscdk.RemovalPolicy_DESTROY,
-	})
+	}))
+
+	return queue, deadLetterQueue
 }
 
-func createStateMachine(stack awscdk.Stack, queue awssqs.Queue, props StackProps) awsstepfunctions.StateMachine {
+const (
+	// sqsMaxDelay is the maximum delay of an SQS message, longer delays are handled by step function wait states
+	sqsMaxDelay = 15 * time.Minute
+	// syncSendMaxAttempts is the number of retries of a failed sync message send, 1s, 2s and 4s apart
+	syncSendMaxAttempts = 3
+)
+
+// syncSchedule returns the period of the EventBridge rule and the delays of the sync messages sent within one period.
+// The period is the least common multiple of the interval and the EventBridge trigger interval,
+// so the cadence stays exact across periods for intervals which don't divide a minute.
+func syncSchedule(interval time.Duration) (time.Duration, []time.Duration) {
 	var (
-		seconds  = int(props.ReloadInterval.Seconds())
-		count    = EventBridgeTriggerIntervalMinutes * 60 / seconds
-		sqsTasks = make([]awsstepfunctionstasks.SqsSendMessage, count)
+		seconds = int(interval.Seconds())
+		base    = EventBridgeTriggerIntervalMinutes * 60
+		period  = seconds / gcd(seconds, base) * base
+		delays  = make([]time.Duration, period/seconds)
 	)
-	for i := 0; i < count; i++ {
-		sqsTasks[i] = awsstepfunctionstasks.NewSqsSendMessage(stack, jsii.String(fmt.Sprintf("Send Delayed SQS Trigger Message - %d seconds", i*seconds)), &awsstepfunctionstasks.SqsSendMessageProps{
+	for i := range delays {
+		delays[i] = time.Duration(i*seconds) * time.Second
+	}
+	return time.Duration(period) * time.Second, delays
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func createStateMachine(scope constructs.Construct, queue awssqs.Queue, props AuthorizerProps) awsstepfunctions.StateMachine {
+	var (
+		_, delays = syncSchedule(props.ReloadInterval)
+		states    = make([]awsstepfunctions.IChainable, 0, len(delays))
+		waited    time.Duration
+	)
+	for _, delay := range delays {
+		if delay-waited > sqsMaxDelay {
+			states = append(states, awsstepfunctions.NewWait(scope, jsii.String(fmt.Sprintf("Wait Until %d seconds", int(delay.Seconds()))), &awsstepfunctions.WaitProps{
+				Time: awsstepfunctions.WaitTime_Duration(awscdk.Duration_Seconds(jsii.Number((delay - waited).Seconds()))),
+			}))
+			waited = delay
+		}
+
+		send := awsstepfunctionstasks.NewSqsSendMessage(scope, jsii.String(fmt.Sprintf("Send Delayed SQS Trigger Message - %d seconds", int(delay.Seconds()))), &awsstepfunctionstasks.SqsSendMessageProps{
 			MessageBody: awsstepfunctions.TaskInput_FromText(jsii.String("Sync")),
 			Queue:       queue,
-			Delay:       awscdk.Duration_Seconds(jsii.Number(i * seconds)),
+			Delay:       awscdk.Duration_Seconds(jsii.Number((delay - waited).Seconds())),
+		})
+		// a failed send would fail the run and skip the remaining syncs of the period, which can be up to an hour long
+		send.AddRetry(&awsstepfunctions.RetryProps{
+			Errors:      jsii.Strings(*awsstepfunctions.Errors_ALL()),
+			Interval:    awscdk.Duration_Seconds(jsii.Number(1)),
+			MaxAttempts: jsii.Number(syncSendMaxAttempts),
+			BackoffRate: jsii.Number(2),
 		})
+		states = append(states, send)
 	}
 
-	definition := awsstepfunctions.Chain_Start(sqsTasks[0])
-	for _, sqsTask := range sqsTasks[1:] {
-		definition = definition.Next(sqsTask)
+	definition := awsstepfunctions.Chain_Start(states[0])
+	for _, state := range states[1:] {
+		definition = definition.Next(state)
 	}
 
-	return awsstepfunctions.NewStateMachine(stack, jsii.String("Sync Looper"), &awsstepfunctions.StateMachineProps{
+	return awsstepfunctions.NewStateMachine(scope, jsii.String("Sync Looper"), override(props.Overrides.SyncLooper, &awsstepfunctions.StateMachineProps{
 		DefinitionBody: awsstepfunctions.ChainDefinitionBody_FromChainable(definition),
 		RemovalPolicy:  awscdk.RemovalPolicy_DESTROY,
-	})
+	}))
 }
 
-func createEventBridgeRule(stack awscdk.Stack, syncLooper awsstepfunctions.StateMachine) {
-	rule := awsevents.NewRule(stack, jsii.String("Run Step Function"), &awsevents.RuleProps{
-		Schedule: awsevents.Schedule_Rate(awscdk.Duration_Minutes(jsii.Number(EventBridgeTriggerIntervalMinutes))),
-	})
+func createEventBridgeRule(scope constructs.Construct, syncLooper awsstepfunctions.StateMachine, props AuthorizerProps) awsevents.Rule {
+	period, _ := syncSchedule(props.ReloadInterval)
+	rule := awsevents.NewRule(scope, jsii.String("Run Step Function"), override(props.Overrides.SyncRule, &awsevents.RuleProps{
+		Schedule: awsevents.Schedule_Rate(awscdk.Duration_Minutes(jsii.Number(period.Minutes()))),
+	}))
 	rule.AddTarget(awseventstargets.NewSfnStateMachine(syncLooper, &awseventstargets.SfnStateMachineProps{}))
 	rule.ApplyRemovalPolicy(awscdk.RemovalPolicy_DESTROY)
+	return rule
 }
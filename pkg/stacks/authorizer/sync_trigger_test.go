@@ -0,0 +1,219 @@
+package authorizer
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2/assertions"
+	"github.com/aws/jsii-runtime-go"
+)
+
+func TestSyncSchedule(t *testing.T) {
+	tcs := []struct {
+		interval time.Duration
+		period   time.Duration
+		delays   []int
+	}{
+		{interval: 5 * time.Second, period: time.Minute, delays: []int{0, 5, 10, 15, 20, 25, 30, 35, 40, 45, 50, 55}},
+		{interval: 10 * time.Second, period: time.Minute, delays: []int{0, 10, 20, 30, 40, 50}},
+		{interval: 30 * time.Second, period: time.Minute, delays: []int{0, 30}},
+		{interval: 45 * time.Second, period: 3 * time.Minute, delays: []int{0, 45, 90, 135}},
+		{interval: 8 * time.Second, period: 2 * time.Minute, delays: []int{0, 8, 16, 24, 32, 40, 48, 56, 64, 72, 80, 88, 96, 104, 112}},
+		{interval: 7 * time.Second, period: 7 * time.Minute, delays: seq(7, 60)},
+		{interval: 11 * time.Second, period: 11 * time.Minute, delays: seq(11, 60)},
+		{interval: 13 * time.Second, period: 13 * time.Minute, delays: seq(13, 60)},
+		{interval: 59 * time.Second, period: 59 * time.Minute, delays: seq(59, 60)},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.interval.String(), func(t *testing.T) {
+			period, delays := syncSchedule(tc.interval)
+			if period != tc.period {
+				t.Fatalf("expected period %s, got %s", tc.period, period)
+			}
+			if len(delays) != len(tc.delays) {
+				t.Fatalf("expected %d delays, got %d", len(tc.delays), len(delays))
+			}
+			for i, delay := range delays {
+				if delay != time.Duration(tc.delays[i])*time.Second {
+					t.Fatalf("expected delay %d to be %ds, got %s", i, tc.delays[i], delay)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncScheduleKeepsExactCadence(t *testing.T) {
+	for seconds := 5; seconds < 60; seconds++ {
+		interval := time.Duration(seconds) * time.Second
+		period, delays := syncSchedule(interval)
+
+		if period%time.Minute != 0 {
+			t.Fatalf("%s: period %s is not a whole number of minutes", interval, period)
+		}
+		if len(delays) > 60 {
+			t.Fatalf("%s: expected at most 60 delays, got %d", interval, len(delays))
+		}
+		// the gap between the last message and the first message of the next period must equal the interval too
+		next := append(delays[1:], period)
+		for i, delay := range delays {
+			if next[i]-delay != interval {
+				t.Fatalf("%s: uneven gap %s after delay %s", interval, next[i]-delay, delay)
+			}
+		}
+	}
+}
+
+func TestSyncTrigger(t *testing.T) {
+	tcs := []struct {
+		interval      time.Duration
+		schedule      string
+		target        map[string]interface{}
+		queues        int
+		stateMachines int
+	}{
+		{
+			interval: 5 * time.Minute,
+			schedule: "rate(5 minutes)",
+			target:   map[string]interface{}{"Fn::GetAtt": []interface{}{assertions.Match_StringLikeRegexp(jsii.String("^SyncLambda")), "Arn"}},
+		},
+		{
+			interval: time.Hour,
+			schedule: "rate(1 hour)",
+			target:   map[string]interface{}{"Fn::GetAtt": []interface{}{assertions.Match_StringLikeRegexp(jsii.String("^SyncLambda")), "Arn"}},
+		},
+		{
+			interval:      30 * time.Second,
+			schedule:      "rate(1 minute)",
+			target:        map[string]interface{}{"Ref": assertions.Match_StringLikeRegexp(jsii.String("^SyncLooper"))},
+			queues:        2,
+			stateMachines: 1,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.interval.String(), func(t *testing.T) {
+			props := testProps()
+			props.ReloadInterval = tc.interval
+
+			template := synthTemplate(t, props)
+			template.ResourceCountIs(jsii.String("AWS::SQS::Queue"), jsii.Number(tc.queues))
+			template.ResourceCountIs(jsii.String("AWS::StepFunctions::StateMachine"), jsii.Number(tc.stateMachines))
+			template.ResourceCountIs(jsii.String("AWS::Events::Rule"), jsii.Number(1))
+			template.HasResourceProperties(jsii.String("AWS::Events::Rule"), map[string]interface{}{
+				"ScheduleExpression": tc.schedule,
+				"Targets": []interface{}{
+					assertions.Match_ObjectLike(&map[string]interface{}{
+						"Arn": tc.target,
+					}),
+				},
+			})
+		})
+	}
+}
+
+func TestSyncLooperDefinition(t *testing.T) {
+	for _, interval := range []time.Duration{10 * time.Second, 45 * time.Second, 59 * time.Second} {
+		t.Run(interval.String(), func(t *testing.T) {
+			props := testProps()
+			props.ReloadInterval = interval
+
+			var (
+				template      = synthTemplate(t, props)
+				definition    = syncLooperDefinition(t, template)
+				period, sends = syncSchedule(interval)
+				elapsed       time.Duration
+				sent          []time.Duration
+			)
+
+			template.HasResourceProperties(jsii.String("AWS::Events::Rule"), map[string]interface{}{
+				"ScheduleExpression": assertions.Match_StringLikeRegexp(jsii.Sprintf("rate\\(%d minutes?\\)", int(period.Minutes()))),
+			})
+
+			// follow the chain and track when each message becomes visible
+			for name := definition.StartAt; name != ""; {
+				state := definition.States[name]
+				switch state.Type {
+				case "Wait":
+					elapsed += time.Duration(state.Seconds) * time.Second
+				case "Task":
+					delay := time.Duration(state.Parameters.DelaySeconds) * time.Second
+					if delay > sqsMaxDelay {
+						t.Fatalf("%s: delay %s exceeds the SQS limit", name, delay)
+					}
+					if len(state.Retry) == 0 || state.Retry[0].MaxAttempts != syncSendMaxAttempts {
+						t.Fatalf("%s: expected %d retries, got %+v", name, syncSendMaxAttempts, state.Retry)
+					}
+					sent = append(sent, elapsed+delay)
+				default:
+					t.Fatalf("%s: unexpected state type %s", name, state.Type)
+				}
+				name = state.Next
+			}
+
+			if len(sent) != len(sends) {
+				t.Fatalf("expected %d messages, got %d", len(sends), len(sent))
+			}
+			for i := range sent {
+				if sent[i] != sends[i] {
+					t.Fatalf("expected message %d after %s, got %s", i, sends[i], sent[i])
+				}
+			}
+		})
+	}
+}
+
+type stateMachineDefinition struct {
+	StartAt string
+	States  map[string]struct {
+		Type       string
+		Next       string
+		Seconds    int
+		Parameters struct {
+			DelaySeconds int
+		}
+		Retry []struct {
+			MaxAttempts int
+		}
+	}
+}
+
+// syncLooperDefinition parses the definition of the sync looper state machine,
+// references resolved on deployment (e.g. the queue URL) are replaced with a placeholder
+func syncLooperDefinition(t *testing.T, template assertions.Template) stateMachineDefinition {
+	t.Helper()
+
+	var (
+		definition stateMachineDefinition
+		raw        string
+	)
+
+	machines := template.FindResources(jsii.String("AWS::StepFunctions::StateMachine"), nil)
+	if len(*machines) != 1 {
+		t.Fatalf("expected one state machine, got %d", len(*machines))
+	}
+	for _, machine := range *machines {
+		join := (*machine)["Properties"].(map[string]interface{})["DefinitionString"].(map[string]interface{})["Fn::Join"].([]interface{})
+		for _, part := range join[1].([]interface{}) {
+			if s, ok := part.(string); ok {
+				raw += s
+				continue
+			}
+			raw += "ref"
+		}
+	}
+
+	if err := json.Unmarshal([]byte(raw), &definition); err != nil {
+		t.Fatal(err)
+	}
+	return definition
+}
+
+func seq(step, count int) []int {
+	s := make([]int, count)
+	for i := range s {
+		s[i] = i * step
+	}
+	return s
+}
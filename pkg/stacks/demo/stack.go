@@ -8,7 +8,7 @@ import (
 	"github.com/aws/jsii-runtime-go"
 )
 
-func NewStack(scope constructs.Construct, id string, authorizerLambda awslambda.Function, props awscdk.StackProps) (awscdk.Stack, error) {
+func NewStack(scope constructs.Construct, id string, authorizerLambda awslambda.IFunction, props awscdk.StackProps) (awscdk.Stack, error) {
 	stack := awscdk.NewStack(scope, &id, &props)
 
 	createAPI(stack, *authorizerLambda.FunctionArn())
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/cloudentity/awsauthorizercdk/pkg/stacks/authorizer"
+	"github.com/go-playground/validator/v10"
+)
+
+// validationErrors are human readable messages of failed stack props validations, one per field
+type validationErrors []string
+
+func (e validationErrors) Error() string {
+	return "invalid stack props:\n  - " + strings.Join(e, "\n  - ")
+}
+
+// describeValidationErrors translates validator errors into messages which refer to context params,
+// other errors are returned unchanged
+func describeValidationErrors(err error) error {
+	var (
+		verrs validator.ValidationErrors
+		msgs  validationErrors
+	)
+
+	if !errors.As(err, &verrs) {
+		return err
+	}
+
+	for _, fe := range verrs {
+		msgs = append(msgs, describeFieldError(fe))
+	}
+	return msgs
+}
+
+func describeFieldError(fe validator.FieldError) string {
+	var (
+		// namespace starts with the name of the validated struct, e.g. AuthorizerProps.issuerURL
+		_, key, _ = strings.Cut(fe.Namespace(), ".")
+		param     = fe.Param()
+	)
+
+	// client secret is not a context param, it's read from an env var
+	if key == "ClientSecret" {
+		key = "ACP_CLIENT_SECRET"
+	}
+
+	switch fe.Tag() {
+	case "required", "required_without_all":
+		return fmt.Sprintf("%s is required (%s)", key, setHint(key))
+	case "required_with":
+		return fmt.Sprintf("%s is required when %s is set (%s)", key, siblingKeys(key, param), setHint(key))
+	case "required_if":
+		field, value, _ := strings.Cut(param, " ")
+		return fmt.Sprintf("%s is required when %s is %s (%s)", key, siblingKey(key, field), value, setHint(key))
+	case "required_if_top":
+		field, value, _ := strings.Cut(param, " ")
+		return fmt.Sprintf("%s is required when %s is %s (%s)", key, contextKeys(field), value, setHint(key))
+	case "lambda_layer_arn":
+		return fmt.Sprintf("%s must be a lambda layer version ARN, e.g. arn:aws:lambda:eu-west-1:123456789012:layer:name:1, got %v", key, fe.Value())
+	case "excluded_with":
+		return fmt.Sprintf("%s can't be used together with %s", key, siblingKeys(key, param))
+	case "excluded_with_top":
+		return fmt.Sprintf("%s can't be used together with %s", key, contextKeys(param))
+	case "excluded_without_all":
+		return fmt.Sprintf("%s requires one of %s", key, siblingKeys(key, param))
+	case "excluded_unless":
+		field, value, _ := strings.Cut(param, " ")
+		return fmt.Sprintf("%s can only be used when %s is %s", key, siblingKey(key, field), value)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s, got %v", key, strings.ReplaceAll(param, " ", ", "), fe.Value())
+	case "min", "gte":
+		return fmt.Sprintf("%s must be at least %s, got %v", key, param, fe.Value())
+	case "max", "lte":
+		return fmt.Sprintf("%s must be at most %s, got %v", key, param, fe.Value())
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s, got %v", key, param, fe.Value())
+	case "gtefield":
+		return fmt.Sprintf("%s must be greater than or equal to %s, got %v", key, siblingKey(key, param), fe.Value())
+	case "reload_interval":
+		return fmt.Sprintf("%s must be a whole number of seconds below 1m or a whole number of minutes, got %v", key, fe.Value())
+	case "http_url":
+		return fmt.Sprintf("%s must be an http or https URL, got %v", key, fe.Value())
+	case "semver":
+		return fmt.Sprintf("%s must be a semantic version, e.g. 2.22.0, got %v", key, fe.Value())
+	case "pem_certificates":
+		return fmt.Sprintf("%s must contain PEM encoded x509 certificates", key)
+	case "pem_certificates_file":
+		return fmt.Sprintf("%s must be a file with PEM encoded x509 certificates, got %v", key, fe.Value())
+	case "file":
+		return fmt.Sprintf("%s must be an existing file, got %v", key, fe.Value())
+	case "s3_uri":
+		return fmt.Sprintf("%s must be an S3 object URI, e.g. s3://bucket/ca.pem, got %v", key, fe.Value())
+	case "vpc_id":
+		return fmt.Sprintf("%s must be a VPC id, e.g. vpc-0123456789abcdef0, got %v", key, fe.Value())
+	case "efs_file_system_id":
+		return fmt.Sprintf("%s must be an EFS file system id, e.g. fs-0123456789abcdef0, got %v", key, fe.Value())
+	case "security_group_id":
+		return fmt.Sprintf("%s must be a security group id, e.g. sg-0123456789abcdef0, got %v", key, fe.Value())
+	case "efs_access_point_arn":
+		return fmt.Sprintf("%s must be an EFS access point ARN, e.g. arn:aws:elasticfilesystem:eu-west-1:123456789012:access-point/fsap-0123456789abcdef0, got %v", key, fe.Value())
+	case "efs_access_point_path":
+		return fmt.Sprintf("%s must be %s, the directory the lambdas keep configuration in, got %v", key, authorizer.EfsApPath, fe.Value())
+	case "secretsmanager_secret_arn":
+		return fmt.Sprintf("%s must be a complete Secrets Manager secret ARN, e.g. arn:aws:secretsmanager:eu-west-1:123456789012:secret:name-AbCdEf, got %v", key, fe.Value())
+	case "kms_key_arn":
+		return fmt.Sprintf("%s must be a KMS key ARN, e.g. arn:aws:kms:eu-west-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab, got %v", key, fe.Value())
+	case "efs_performance_mode":
+		return fmt.Sprintf("%s can't be maxIO with elastic throughput nor One Zone", key)
+	case "one_zone":
+		return fmt.Sprintf("%s must have exactly one availability zone for a One Zone file system, got %v", key, fe.Value())
+	case "availability_zone":
+		return fmt.Sprintf("%s must be an availability zone name, e.g. eu-west-1a, got %v", key, fe.Value())
+	case "unique":
+		return fmt.Sprintf("%s must not have duplicates, got %v", key, fe.Value())
+	case "posix_id":
+		return fmt.Sprintf("%s must be a POSIX user or group id, e.g. 1001, got %v", key, fe.Value())
+	case "posix_permissions":
+		return fmt.Sprintf("%s must be octal POSIX permissions, e.g. 750, got %v", key, fe.Value())
+	case "s3_bucket_name":
+		return fmt.Sprintf("%s must be a valid S3 bucket name of at most 48 characters (the region is appended to it), got %v", key, fe.Value())
+	case "whole_minutes":
+		return fmt.Sprintf("%s must be a whole number of minutes, got %v", key, fe.Value())
+	case "whole_hours":
+		return fmt.Sprintf("%s must be a whole number of hours, got %v", key, fe.Value())
+	case "whole_seconds":
+		return fmt.Sprintf("%s must be a whole number of seconds, got %v", key, fe.Value())
+	case "not_reserved_env":
+		return fmt.Sprintf("%s sets %v which is reserved and set by the stack", key, fe.Value())
+	}
+	return fmt.Sprintf("%s is invalid, failed %s validation", key, fe.Tag())
+}
+
+// setHint tells how to set a param
+func setHint(key string) string {
+	if key == "ACP_CLIENT_SECRET" {
+		return "set ACP_CLIENT_SECRET env var, -c clientSecretArn=... or -c createClientSecret=true"
+	}
+	for alias, path := range contextAliases {
+		if path == key {
+			return fmt.Sprintf("set -c %s=...", alias)
+		}
+	}
+	if strings.Contains(key, ".") {
+		return fmt.Sprintf("set %s in the config file", key)
+	}
+	return fmt.Sprintf("set -c %s=...", key)
+}
+
+// siblingKey translates a go field name of a validation param to the key of a field next to key,
+// e.g. ThroughputMode next to efsSettings.provisionedThroughput is efsSettings.throughputMode
+func siblingKey(key, name string) string {
+	i := strings.LastIndex(key, ".")
+	if i < 0 {
+		return contextKeys(name)
+	}
+	return key[:i+1] + strings.ToLower(name[:1]) + name[1:]
+}
+
+// siblingKeys translates space separated go field names of a validation param to the keys of fields next to key
+func siblingKeys(key, param string) string {
+	var (
+		names = strings.Fields(param)
+		keys  = make([]string, len(names))
+	)
+
+	for i, name := range names {
+		keys[i] = siblingKey(key, name)
+	}
+	return strings.Join(keys, ", ")
+}
+
+// contextKeys translates space separated go field names of a validation param to context param names
+func contextKeys(param string) string {
+	var (
+		names = strings.Fields(param)
+		keys  = make([]string, len(names))
+		t     = reflect.TypeOf(authorizer.AuthorizerProps{})
+	)
+
+	for i, name := range names {
+		if f, ok := t.FieldByName(name); ok && f.Tag.Get("json") != "-" {
+			keys[i] = strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+			continue
+		}
+		keys[i] = strings.ToLower(name[:1]) + name[1:]
+	}
+	return strings.Join(keys, ", ")
+}
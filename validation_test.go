@@ -0,0 +1,333 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/jsii-runtime-go"
+	"github.com/cloudentity/awsauthorizercdk/pkg/stacks/authorizer"
+)
+
+func TestDescribeValidationErrors(t *testing.T) {
+	var (
+		scope       = awscdk.NewStack(awscdk.NewApp(nil), jsii.String("Validation"), nil)
+		invalidFile = writeTestFile(t, "ca.pem", "not a certificate")
+		missingFile = filepath.Join(t.TempDir(), "ca.pem")
+		efs         = func(p *authorizer.AuthorizerProps) {
+			p.VpcID = "vpc-0123456789abcdef0"
+			p.EFSFileSystemID = "fs-0123456789abcdef0"
+			p.EFSSecurityGroupID = "sg-0123456789abcdef0"
+		}
+	)
+
+	tcs := []struct {
+		rule     string
+		props    func(*authorizer.AuthorizerProps)
+		messages []string
+	}{
+		{
+			rule:     "required",
+			props:    func(p *authorizer.AuthorizerProps) { p.ClientID = "" },
+			messages: []string{"clientID is required (set -c clientID=...)"},
+		},
+		{
+			rule:     "required_without_all",
+			props:    func(p *authorizer.AuthorizerProps) { p.ClientSecret = "" },
+			messages: []string{"ACP_CLIENT_SECRET is required (set ACP_CLIENT_SECRET env var, -c clientSecretArn=... or -c createClientSecret=true)"},
+		},
+		{
+			rule: "required_with",
+			props: func(p *authorizer.AuthorizerProps) {
+				p.EFSFileSystemID = "fs-0123456789abcdef0"
+				p.EFSSecurityGroupID = "sg-0123456789abcdef0"
+			},
+			messages: []string{"vpcID is required when efsFileSystemID is set (set -c vpcID=...)"},
+		},
+		{
+			rule:     "required_with nested",
+			props:    func(p *authorizer.AuthorizerProps) { p.AuthorizerProvisionedConcurrency.MaxCapacity = 2 },
+			messages: []string{"authorizerProvisionedConcurrency.minCapacity is required when authorizerProvisionedConcurrency.maxCapacity is set (set -c authorizerProvisionedConcurrency=...)"},
+		},
+		{
+			rule: "required_if",
+			props: func(p *authorizer.AuthorizerProps) {
+				p.ClientSecret = ""
+				p.CreateClientSecret = true
+				p.RotateClientSecret = true
+			},
+			messages: []string{"rotationZip is required when rotateClientSecret is true (set -c rotationZip=...)"},
+		},
+		{
+			rule:     "required_if nested",
+			props:    func(p *authorizer.AuthorizerProps) { p.EFSSettings.ThroughputMode = "provisioned" },
+			messages: []string{"efsSettings.provisionedThroughput is required when efsSettings.throughputMode is provisioned (set efsSettings.provisionedThroughput in the config file)"},
+		},
+		{
+			rule:     "required_if_top",
+			props:    func(p *authorizer.AuthorizerProps) { p.ConfigurationStore = authorizer.ConfigurationStoreAppConfig },
+			messages: []string{"appConfigSettings.extensionLayerArn is required when configurationStore is appconfig (set appConfigSettings.extensionLayerArn in the config file)"},
+		},
+		{
+			rule: "lambda_layer_arn",
+			props: func(p *authorizer.AuthorizerProps) {
+				p.ConfigurationStore = authorizer.ConfigurationStoreAppConfig
+				p.AppConfigSettings.ExtensionLayerArn = "AWS-AppConfig-Extension:128"
+			},
+			messages: []string{"appConfigSettings.extensionLayerArn must be a lambda layer version ARN, e.g. arn:aws:lambda:eu-west-1:123456789012:layer:name:1, got AWS-AppConfig-Extension:128"},
+		},
+		{
+			rule:     "excluded_with",
+			props:    func(p *authorizer.AuthorizerProps) { p.CreateClientSecret = true },
+			messages: []string{"ACP_CLIENT_SECRET can't be used together with clientSecretArn, createClientSecret"},
+		},
+		{
+			rule: "excluded_with_top",
+			props: func(p *authorizer.AuthorizerProps) {
+				efs(p)
+				p.EFSSettings.OneZone = true
+				p.EFSSettings.AvailabilityZones = []string{"eu-west-1a"}
+			},
+			messages: []string{
+				"efsSettings.availabilityZones can't be used together with efsFileSystemID",
+				"efsSettings.oneZone can't be used together with efsFileSystemID",
+			},
+		},
+		{
+			rule: "excluded_without_all",
+			props: func(p *authorizer.AuthorizerProps) {
+				p.RotateClientSecret = true
+				p.RotationZip = "rotation.zip"
+			},
+			messages: []string{"rotateClientSecret requires one of clientSecretArn, createClientSecret"},
+		},
+		{
+			rule: "excluded_unless",
+			props: func(p *authorizer.AuthorizerProps) {
+				p.ConfigurationStore = authorizer.ConfigurationStoreS3
+				p.VpcID = "vpc-0123456789abcdef0"
+			},
+			messages: []string{"vpcID can only be used when configurationStore is efs"},
+		},
+		{
+			rule: "excluded_unless nested",
+			props: func(p *authorizer.AuthorizerProps) {
+				p.EFSSettings.ThroughputMode = "elastic"
+				p.EFSSettings.ProvisionedThroughput = 10
+			},
+			messages: []string{"efsSettings.provisionedThroughput can only be used when efsSettings.throughputMode is provisioned"},
+		},
+		{
+			rule:     "oneof",
+			props:    func(p *authorizer.AuthorizerProps) { p.LoggingLevel = "trace" },
+			messages: []string{"loggingLevel must be one of debug, info, warn, error, got trace"},
+		},
+		{
+			rule:     "min",
+			props:    func(p *authorizer.AuthorizerProps) { p.AuthorizerLambdaSettings.MemorySize = 64 },
+			messages: []string{"authorizerLambdaSettings.memorySize must be at least 128, got 64"},
+		},
+		{
+			rule: "max",
+			props: func(p *authorizer.AuthorizerProps) {
+				p.AuthorizerProvisionedConcurrency.MinCapacity = 1
+				p.AuthorizerProvisionedConcurrency.MaxCapacity = 2
+				p.AuthorizerProvisionedConcurrency.UtilizationTarget = 0.95
+			},
+			messages: []string{"authorizerProvisionedConcurrency.utilizationTarget must be at most 0.9, got 0.95"},
+		},
+		{
+			rule: "gtefield",
+			props: func(p *authorizer.AuthorizerProps) {
+				p.AuthorizerProvisionedConcurrency.MinCapacity = 3
+				p.AuthorizerProvisionedConcurrency.MaxCapacity = 2
+			},
+			messages: []string{"authorizerProvisionedConcurrency.maxCapacity must be greater than or equal to authorizerProvisionedConcurrency.minCapacity, got 2"},
+		},
+		{
+			rule:     "reload_interval",
+			props:    func(p *authorizer.AuthorizerProps) { p.ReloadInterval = 90 * time.Second },
+			messages: []string{"reloadInterval must be a whole number of seconds below 1m or a whole number of minutes, got 1m30s"},
+		},
+		{
+			rule:     "http_url",
+			props:    func(p *authorizer.AuthorizerProps) { p.IssuerURL = "example.authz.cloudentity.io" },
+			messages: []string{"issuerURL must be an http or https URL, got example.authz.cloudentity.io"},
+		},
+		{
+			rule:     "semver",
+			props:    func(p *authorizer.AuthorizerProps) { p.Version = "latest" },
+			messages: []string{"version must be a semantic version, e.g. 2.22.0, got latest"},
+		},
+		{
+			rule:     "pem_certificates",
+			props:    func(p *authorizer.AuthorizerProps) { p.HTTPClientRootCA = "not a certificate" },
+			messages: []string{"httpClientRootCA must contain PEM encoded x509 certificates"},
+		},
+		{
+			rule:     "file",
+			props:    func(p *authorizer.AuthorizerProps) { p.HTTPClientRootCAFile = missingFile },
+			messages: []string{"httpClientRootCAFile must be an existing file, got " + missingFile},
+		},
+		{
+			rule:     "pem_certificates_file",
+			props:    func(p *authorizer.AuthorizerProps) { p.HTTPClientRootCAFile = invalidFile },
+			messages: []string{"httpClientRootCAFile must be a file with PEM encoded x509 certificates, got " + invalidFile},
+		},
+		{
+			rule:     "s3_uri",
+			props:    func(p *authorizer.AuthorizerProps) { p.HTTPClientRootCAS3URI = "https://pki.s3.amazonaws.com/ca.pem" },
+			messages: []string{"httpClientRootCAS3URI must be an S3 object URI, e.g. s3://bucket/ca.pem, got https://pki.s3.amazonaws.com/ca.pem"},
+		},
+		{
+			rule:     "vpc_id",
+			props:    func(p *authorizer.AuthorizerProps) { p.VpcID = "subnet-0123456789abcdef0" },
+			messages: []string{"vpcID must be a VPC id, e.g. vpc-0123456789abcdef0, got subnet-0123456789abcdef0"},
+		},
+		{
+			rule: "efs_file_system_id",
+			props: func(p *authorizer.AuthorizerProps) {
+				efs(p)
+				p.EFSFileSystemID = "efs-0123"
+			},
+			messages: []string{"efsFileSystemID must be an EFS file system id, e.g. fs-0123456789abcdef0, got efs-0123"},
+		},
+		{
+			rule: "security_group_id",
+			props: func(p *authorizer.AuthorizerProps) {
+				efs(p)
+				p.EFSSecurityGroupID = "default"
+			},
+			messages: []string{"efsSecurityGroupID must be a security group id, e.g. sg-0123456789abcdef0, got default"},
+		},
+		{
+			rule: "efs_access_point_arn",
+			props: func(p *authorizer.AuthorizerProps) {
+				efs(p)
+				p.EFSAccessPointArn = "fsap-0123456789abcdef0"
+				p.EFSAccessPointPath = authorizer.EfsApPath
+			},
+			messages: []string{"efsAccessPointArn must be an EFS access point ARN, e.g. arn:aws:elasticfilesystem:eu-west-1:123456789012:access-point/fsap-0123456789abcdef0, got fsap-0123456789abcdef0"},
+		},
+		{
+			rule: "efs_access_point_path",
+			props: func(p *authorizer.AuthorizerProps) {
+				efs(p)
+				p.EFSAccessPointArn = "arn:aws:elasticfilesystem:eu-west-1:123456789012:access-point/fsap-0123456789abcdef0"
+				p.EFSAccessPointPath = "/config"
+			},
+			messages: []string{"efsAccessPointPath must be /ceauthconfig, the directory the lambdas keep configuration in, got /config"},
+		},
+		{
+			rule: "secretsmanager_secret_arn",
+			props: func(p *authorizer.AuthorizerProps) {
+				p.ClientSecret = ""
+				p.ClientSecretArn = "acp-client-secret"
+			},
+			messages: []string{"clientSecretArn must be a complete Secrets Manager secret ARN, e.g. arn:aws:secretsmanager:eu-west-1:123456789012:secret:name-AbCdEf, got acp-client-secret"},
+		},
+		{
+			rule:     "kms_key_arn",
+			props:    func(p *authorizer.AuthorizerProps) { p.EFSSettings.KMSKeyArn = "alias/efs" },
+			messages: []string{"efsSettings.kmsKeyArn must be a KMS key ARN, e.g. arn:aws:kms:eu-west-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab, got alias/efs"},
+		},
+		{
+			rule: "efs_performance_mode",
+			props: func(p *authorizer.AuthorizerProps) {
+				p.EFSSettings.PerformanceMode = "maxIO"
+				p.EFSSettings.ThroughputMode = "elastic"
+			},
+			messages: []string{"efsSettings.performanceMode can't be maxIO with elastic throughput nor One Zone"},
+		},
+		{
+			rule: "one_zone",
+			props: func(p *authorizer.AuthorizerProps) {
+				p.EFSSettings.OneZone = true
+				p.EFSSettings.AvailabilityZones = []string{"eu-west-1a", "eu-west-1b"}
+			},
+			messages: []string{"efsSettings.availabilityZones must have exactly one availability zone for a One Zone file system, got [eu-west-1a eu-west-1b]"},
+		},
+		{
+			rule: "unique",
+			props: func(p *authorizer.AuthorizerProps) {
+				p.EFSSettings.AvailabilityZones = []string{"eu-west-1a", "eu-west-1a"}
+			},
+			messages: []string{"efsSettings.availabilityZones must not have duplicates, got [eu-west-1a eu-west-1a]"},
+		},
+		{
+			rule:     "availability_zone",
+			props:    func(p *authorizer.AuthorizerProps) { p.EFSSettings.AvailabilityZones = []string{"eu-west-1"} },
+			messages: []string{"efsSettings.availabilityZones[0] must be an availability zone name, e.g. eu-west-1a, got eu-west-1"},
+		},
+		{
+			rule:     "posix_id",
+			props:    func(p *authorizer.AuthorizerProps) { p.EFSSettings.UID = "root" },
+			messages: []string{"efsSettings.uid must be a POSIX user or group id, e.g. 1001, got root"},
+		},
+		{
+			rule:     "posix_permissions",
+			props:    func(p *authorizer.AuthorizerProps) { p.EFSSettings.Permissions = "rwx" },
+			messages: []string{"efsSettings.permissions must be octal POSIX permissions, e.g. 750, got rwx"},
+		},
+		{
+			rule:     "s3_bucket_name",
+			props:    func(p *authorizer.AuthorizerProps) { p.S3BucketName = "My_Bucket" },
+			messages: []string{"s3BucketName must be a valid S3 bucket name of at most 48 characters (the region is appended to it), got My_Bucket"},
+		},
+		{
+			rule: "whole_minutes",
+			props: func(p *authorizer.AuthorizerProps) {
+				p.ConfigurationStore = authorizer.ConfigurationStoreAppConfig
+				p.AppConfigSettings.ExtensionLayerArn = "arn:aws:lambda:eu-west-1:434848589818:layer:AWS-AppConfig-Extension:128"
+				p.AppConfigSettings.DeploymentDuration = 90 * time.Second
+			},
+			messages: []string{"appConfigSettings.deploymentDuration must be a whole number of minutes, got 1m30s"},
+		},
+		{
+			rule:     "whole_hours",
+			props:    func(p *authorizer.AuthorizerProps) { p.ClientSecretRotationInterval = 4*time.Hour + 30*time.Minute },
+			messages: []string{"clientSecretRotationInterval must be a whole number of hours, got 4h30m0s"},
+		},
+		{
+			rule:     "whole_seconds",
+			props:    func(p *authorizer.AuthorizerProps) { p.SyncLambdaSettings.Timeout = 1500 * time.Millisecond },
+			messages: []string{"syncLambdaSettings.timeout must be a whole number of seconds, got 1.5s"},
+		},
+		{
+			rule:     "not_reserved_env",
+			props:    func(p *authorizer.AuthorizerProps) { p.AuthorizerEnv = map[string]string{"LOGGING_LEVEL": "debug"} },
+			messages: []string{"authorizerEnv[LOGGING_LEVEL] sets LOGGING_LEVEL which is reserved and set by the stack"},
+		},
+	}
+
+	for i, tc := range tcs {
+		t.Run(tc.rule, func(t *testing.T) {
+			props := authorizer.AuthorizerProps{
+				ClientID:     "client-id",
+				ClientSecret: "client-secret",
+				IssuerURL:    "https://example.authz.cloudentity.io/example/system",
+				Version:      "2.22.0",
+			}
+			tc.props(&props)
+
+			_, err := authorizer.NewAuthorizer(scope, fmt.Sprintf("Authorizer%d", i), props)
+			if err == nil {
+				t.Fatal("expected validation errors")
+			}
+
+			msgs, ok := describeValidationErrors(err).(validationErrors)
+			if !ok {
+				t.Fatalf("expected validation errors, got %v", err)
+			}
+			sort.Strings(msgs)
+			if !reflect.DeepEqual([]string(msgs), tc.messages) {
+				t.Errorf("expected messages:\n%s\ngot:\n%s", strings.Join(tc.messages, "\n"), strings.Join(msgs, "\n"))
+			}
+		})
+	}
+}